@@ -0,0 +1,415 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/essentialkaos/ek/v13/fsutil"
+	"github.com/essentialkaos/ek/v13/system"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const (
+	xattrACLAccess  = "system.posix_acl_access"
+	xattrACLDefault = "system.posix_acl_default"
+)
+
+const (
+	aclVersion     = 2
+	aclUndefID     = 0xFFFFFFFF
+	aclTagUser     = 0x02
+	aclTagGroup    = 0x08
+	aclTagUserObj  = 0x01
+	aclTagGroupObj = 0x04
+	aclTagMask     = 0x10
+	aclTagOther    = 0x20
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// aclEntry contains info about ACL entry
+type aclEntry struct {
+	Tag  uint16
+	Perm uint16
+	ID   uint32
+}
+
+// aclEntrySpec contains ACL entry from action argument
+type aclEntrySpec struct {
+	Raw     string
+	Tag     uint16
+	Perm    uint16
+	ID      uint32
+	HasPerm bool
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// ACL is action processor for "acl"
+func ACL(action *recipe.Action) error {
+	return checkACL(action, false)
+}
+
+// ACLDefault is action processor for "acl-default"
+func ACLDefault(action *recipe.Action) error {
+	return checkACL(action, true)
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// checkACL checks access or default ACL of object
+func checkACL(action *recipe.Action, isDefault bool) error {
+	path, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	var specs []*aclEntrySpec
+
+	for index := 1; index < len(action.Arguments); index++ {
+		entry, _ := action.GetS(index)
+		spec, err := parseACLEntrySpec(entry)
+
+		if err != nil {
+			return err
+		}
+
+		specs = append(specs, spec)
+	}
+
+	if !fsutil.IsExist(path) {
+		return fmt.Errorf("Object %s doesn't exist", path)
+	}
+
+	acl, err := readACL(path, isDefault)
+
+	if err != nil {
+		return err
+	}
+
+	aclType := "ACL"
+
+	if isDefault {
+		aclType = "Default ACL"
+	}
+
+	for _, spec := range specs {
+		hasEntry := spec.IsMatch(acl)
+
+		switch {
+		case !action.Negative && !hasEntry:
+			return fmt.Errorf(
+				"%s of %s doesn't contain entry %s\n%s",
+				aclType, path, spec.Raw, formatACL(path, acl, isDefault),
+			)
+		case action.Negative && hasEntry:
+			return fmt.Errorf(
+				"%s of %s contains entry %s\n%s",
+				aclType, path, spec.Raw, formatACL(path, acl, isDefault),
+			)
+		}
+	}
+
+	return nil
+}
+
+// IsMatch returns true if ACL contains entry matching spec. Permissions of named
+// user, named group and owning group entries are limited by mask entry in the same
+// way as getfacl shows them in "#effective" comment.
+func (s *aclEntrySpec) IsMatch(acl []aclEntry) bool {
+	mask, hasMask := getACLMask(acl)
+
+	for _, e := range acl {
+		if e.Tag != s.Tag || e.ID != s.ID {
+			continue
+		}
+
+		return !s.HasPerm || getEffectivePerm(e, mask, hasMask) == s.Perm
+	}
+
+	return false
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// parseACLEntrySpec parses ACL entry in getfacl format (e.g. user:nginx:rwx)
+func parseACLEntrySpec(entry string) (*aclEntrySpec, error) {
+	parts := strings.Split(entry, ":")
+
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("Invalid ACL entry %q", entry)
+	}
+
+	var id int
+	var err error
+
+	spec := &aclEntrySpec{Raw: entry, ID: aclUndefID}
+	qualifier := parts[1]
+
+	switch parts[0] {
+	case "user", "u":
+		spec.Tag = aclTagUserObj
+
+		if qualifier != "" {
+			spec.Tag = aclTagUser
+			id, err = lookupUID(qualifier)
+
+			if err != nil {
+				return nil, err
+			}
+
+			spec.ID = uint32(id)
+		}
+	case "group", "g":
+		spec.Tag = aclTagGroupObj
+
+		if qualifier != "" {
+			spec.Tag = aclTagGroup
+			id, err = lookupGID(qualifier)
+
+			if err != nil {
+				return nil, err
+			}
+
+			spec.ID = uint32(id)
+		}
+	case "mask", "m":
+		spec.Tag = aclTagMask
+	case "other", "o":
+		spec.Tag = aclTagOther
+	default:
+		return nil, fmt.Errorf("Invalid ACL entry %q: unknown entry type %q", entry, parts[0])
+	}
+
+	if qualifier != "" && (spec.Tag == aclTagMask || spec.Tag == aclTagOther) {
+		return nil, fmt.Errorf("Invalid ACL entry %q: %s entry can't have qualifier", entry, parts[0])
+	}
+
+	if len(parts) == 3 {
+		spec.Perm, err = parseACLPerm(parts[2])
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ACL entry %q: %v", entry, err)
+		}
+
+		spec.HasPerm = true
+	}
+
+	return spec, nil
+}
+
+// parseACLPerm parses permissions in rwx format
+func parseACLPerm(v string) (uint16, error) {
+	var perm uint16
+
+	if v == "" {
+		return 0, fmt.Errorf("permissions are empty")
+	}
+
+	for _, r := range v {
+		switch r {
+		case 'r':
+			perm |= 4
+		case 'w':
+			perm |= 2
+		case 'x':
+			perm |= 1
+		case '-':
+			// nothing to do
+		default:
+			return 0, fmt.Errorf("unknown permission %q", r)
+		}
+	}
+
+	return perm, nil
+}
+
+// readACL reads access or default ACL of object. If object doesn't have access
+// ACL, minimal ACL will be created using object mode.
+func readACL(path string, isDefault bool) ([]aclEntry, error) {
+	name := xattrACLAccess
+
+	if isDefault {
+		name = xattrACLDefault
+	}
+
+	data, err := getXattr(path, name)
+
+	if errors.Is(err, syscall.ENODATA) || errors.Is(err, syscall.ENOTSUP) {
+		if isDefault {
+			return nil, nil
+		}
+
+		return getMinimalACL(path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read ACL of %s: %v", path, err)
+	}
+
+	acl, err := parseACL(data)
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse ACL of %s: %v", path, err)
+	}
+
+	return acl, nil
+}
+
+// parseACL parses ACL stored in extended attribute
+func parseACL(data []byte) ([]aclEntry, error) {
+	if len(data) < 4 || (len(data)-4)%8 != 0 ||
+		binary.LittleEndian.Uint32(data) != aclVersion {
+		return nil, fmt.Errorf("unsupported format")
+	}
+
+	var acl []aclEntry
+
+	for i := 4; i < len(data); i += 8 {
+		acl = append(acl, aclEntry{
+			Tag:  binary.LittleEndian.Uint16(data[i:]),
+			Perm: binary.LittleEndian.Uint16(data[i+2:]),
+			ID:   binary.LittleEndian.Uint32(data[i+4:]),
+		})
+	}
+
+	return acl, nil
+}
+
+// getMinimalACL returns ACL equivalent to object mode
+func getMinimalACL(path string) ([]aclEntry, error) {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read info about %s: %v", path, err)
+	}
+
+	mode := uint16(info.Mode().Perm())
+
+	return []aclEntry{
+		{Tag: aclTagUserObj, Perm: mode >> 6 & 7, ID: aclUndefID},
+		{Tag: aclTagGroupObj, Perm: mode >> 3 & 7, ID: aclUndefID},
+		{Tag: aclTagOther, Perm: mode & 7, ID: aclUndefID},
+	}, nil
+}
+
+// formatACL formats ACL in getfacl format
+func formatACL(path string, acl []aclEntry, isDefault bool) string {
+	var result strings.Builder
+
+	fmt.Fprintf(&result, "# file: %s\n", path)
+
+	if len(acl) == 0 {
+		result.WriteString("# ACL is empty")
+		return result.String()
+	}
+
+	prefix := ""
+	mask, hasMask := getACLMask(acl)
+
+	if isDefault {
+		prefix = "default:"
+	}
+
+	for i, e := range acl {
+		var tag, qualifier string
+
+		switch e.Tag {
+		case aclTagUserObj:
+			tag = "user"
+		case aclTagUser:
+			tag, qualifier = "user", getUsername(int(e.ID))
+		case aclTagGroupObj:
+			tag = "group"
+		case aclTagGroup:
+			tag, qualifier = "group", getGroupname(int(e.ID))
+		case aclTagMask:
+			tag = "mask"
+		case aclTagOther:
+			tag = "other"
+		default:
+			tag = fmt.Sprintf("unknown(%d)", e.Tag)
+		}
+
+		fmt.Fprintf(&result, "%s%s:%s:%s", prefix, tag, qualifier, formatACLPerm(e.Perm))
+
+		if effective := getEffectivePerm(e, mask, hasMask); effective != e.Perm {
+			fmt.Fprintf(&result, "\t#effective:%s", formatACLPerm(effective))
+		}
+
+		if i != len(acl)-1 {
+			result.WriteString("\n")
+		}
+	}
+
+	return result.String()
+}
+
+// getACLMask returns permissions from mask entry
+func getACLMask(acl []aclEntry) (uint16, bool) {
+	for _, e := range acl {
+		if e.Tag == aclTagMask {
+			return e.Perm, true
+		}
+	}
+
+	return 0, false
+}
+
+// getEffectivePerm returns entry permissions limited by mask
+func getEffectivePerm(e aclEntry, mask uint16, hasMask bool) uint16 {
+	switch {
+	case !hasMask:
+		return e.Perm
+	case e.Tag == aclTagUser, e.Tag == aclTagGroup, e.Tag == aclTagGroupObj:
+		return e.Perm & mask
+	}
+
+	return e.Perm
+}
+
+// formatACLPerm formats permissions in rwx format
+func formatACLPerm(perm uint16) string {
+	result := []byte("---")
+
+	if perm&4 != 0 {
+		result[0] = 'r'
+	}
+
+	if perm&2 != 0 {
+		result[1] = 'w'
+	}
+
+	if perm&1 != 0 {
+		result[2] = 'x'
+	}
+
+	return string(result)
+}
+
+// getGroupname returns name of group with given GID
+func getGroupname(gid int) string {
+	group, err := system.LookupGroup(strconv.Itoa(gid))
+
+	if err != nil {
+		return strconv.Itoa(gid)
+	}
+
+	return group.Name
+}
@@ -0,0 +1,908 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/fsutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+
+	. "github.com/essentialkaos/check"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+func Test(t *testing.T) { TestingT(t) }
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+type ActionSuite struct{}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+var _ = Suite(&ActionSuite{})
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+func (s *ActionSuite) TestCopyObject(c *C) {
+	dir := c.MkDir()
+
+	c.Assert(os.Mkdir(dir+"/src", 0750), IsNil)
+	c.Assert(os.WriteFile(dir+"/src/file", []byte("test"), 0640), IsNil)
+	c.Assert(os.Link(dir+"/src/file", dir+"/src/link"), IsNil)
+	c.Assert(os.Symlink("file", dir+"/src/symlink"), IsNil)
+	c.Assert(syscall.Mkfifo(dir+"/src/fifo", 0600), IsNil)
+	c.Assert(os.Chmod(dir+"/src/fifo", 0620), IsNil)
+
+	c.Assert(copyObject(dir+"/src", dir+"/dst"), IsNil)
+
+	data, err := os.ReadFile(dir + "/dst/file")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "test")
+
+	fileInfo, err := os.Stat(dir + "/dst/file")
+	c.Assert(err, IsNil)
+	c.Assert(fileInfo.Mode(), Equals, os.FileMode(0640))
+
+	linkInfo, err := os.Stat(dir + "/dst/link")
+	c.Assert(err, IsNil)
+	c.Assert(os.SameFile(fileInfo, linkInfo), Equals, true)
+
+	target, err := os.Readlink(dir + "/dst/symlink")
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, "file")
+
+	fifoInfo, err := os.Lstat(dir + "/dst/fifo")
+	c.Assert(err, IsNil)
+	c.Assert(fifoInfo.Mode(), Equals, os.ModeNamedPipe|0620)
+
+	dirInfo, err := os.Stat(dir + "/dst")
+	c.Assert(err, IsNil)
+	c.Assert(dirInfo.Mode(), Equals, os.ModeDir|0750)
+
+	c.Assert(copyObject(dir+"/unknown", dir+"/dst1"), NotNil)
+	c.Assert(copyObject(dir+"/src", dir+"/dst"), NotNil)
+}
+
+func (s *ActionSuite) TestCopyDevice(c *C) {
+	if os.Geteuid() != 0 {
+		c.Skip("Creating devices requires root privileges")
+	}
+
+	dir := c.MkDir()
+
+	c.Assert(copyObject("/dev/null", dir+"/null"), IsNil)
+
+	origInfo, err := os.Lstat("/dev/null")
+	c.Assert(err, IsNil)
+	copyInfo, err := os.Lstat(dir + "/null")
+	c.Assert(err, IsNil)
+
+	c.Assert(copyInfo.Mode(), Equals, origInfo.Mode())
+	c.Assert(
+		copyInfo.Sys().(*syscall.Stat_t).Rdev,
+		Equals, origInfo.Sys().(*syscall.Stat_t).Rdev,
+	)
+}
+
+func (s *ActionSuite) TestSnapshot(c *C) {
+	dir := c.MkDir()
+
+	c.Assert(os.Mkdir(dir+"/data", 0750), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file1", []byte("test"), 0640), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file2", []byte("test"), 0640), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file3", []byte("test"), 0640), IsNil)
+	c.Assert(os.Symlink("file1", dir+"/data/link"), IsNil)
+
+	r := recipe.NewRecipe(dir + "/test.recipe")
+	r.Dir = dir
+
+	cmd := recipe.NewCommand([]string{"echo"}, 1)
+	c.Assert(r.AddCommand(cmd, "", false), IsNil)
+
+	snapshot := &recipe.Action{Name: "snapshot", Arguments: []string{"test", dir + "/data"}}
+	created := &recipe.Action{Name: "snapshot-created", Arguments: []string{"test"}}
+	deleted := &recipe.Action{Name: "snapshot-deleted", Arguments: []string{"test"}}
+	changed := &recipe.Action{Name: "snapshot-changed", Arguments: []string{"test"}}
+
+	for _, a := range []*recipe.Action{snapshot, created, deleted, changed} {
+		c.Assert(cmd.AddAction(a), IsNil)
+	}
+
+	c.Assert(Snapshot(snapshot), IsNil)
+
+	c.Assert(SnapshotCreated(created), IsNil)
+	c.Assert(SnapshotDeleted(deleted), IsNil)
+	c.Assert(SnapshotChanged(changed), IsNil)
+
+	c.Assert(os.WriteFile(dir+"/data/file1", []byte("test1"), 0640), IsNil)
+	c.Assert(os.Chmod(dir+"/data/file2", 0600), IsNil)
+	c.Assert(os.Remove(dir+"/data/file3"), IsNil)
+	c.Assert(os.Remove(dir+"/data/link"), IsNil)
+	c.Assert(os.Symlink("file2", dir+"/data/link"), IsNil)
+	c.Assert(os.Mkdir(dir+"/data/dir", 0750), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/dir/file4", []byte("test"), 0640), IsNil)
+
+	created.Arguments = []string{"test", dir + "/data/dir", dir + "/data/dir/file4"}
+	c.Assert(SnapshotCreated(created), IsNil)
+	created.Arguments = []string{"test", dir + "/data/dir"}
+	c.Assert(SnapshotCreated(created), ErrorMatches, `Objects in .*/data were unexpectedly created: dir/file4`)
+	created.Arguments = []string{"test", dir + "/data/dir", dir + "/data/dir/file4", dir + "/data/file5"}
+	c.Assert(SnapshotCreated(created), ErrorMatches, `Objects in .*/data were not created: file5`)
+
+	deleted.Arguments = []string{"test", dir + "/data/file3"}
+	c.Assert(SnapshotDeleted(deleted), IsNil)
+
+	changed.Arguments = []string{"test", dir + "/data/file1", dir + "/data/file2", dir + "/data/link"}
+	c.Assert(SnapshotChanged(changed), IsNil)
+	changed.Arguments = []string{"test"}
+	c.Assert(SnapshotChanged(changed), ErrorMatches, `Objects in .*/data were unexpectedly changed: file1, file2, link`)
+
+	deleted.Negative = true
+	deleted.Arguments = []string{"test", dir + "/data/file1"}
+	c.Assert(SnapshotDeleted(deleted), IsNil)
+	deleted.Arguments = []string{"test", dir + "/data/file1", dir + "/data/file3"}
+	c.Assert(SnapshotDeleted(deleted), ErrorMatches, `Objects in .*/data were deleted: file3`)
+	deleted.Arguments = []string{"test"}
+	c.Assert(SnapshotDeleted(deleted), ErrorMatches, `Negative form of action "snapshot-deleted" requires at least one path`)
+
+	changed.Arguments = []string{"unknown"}
+	c.Assert(SnapshotChanged(changed), ErrorMatches, `Snapshot "unknown" doesn't exist`)
+
+	snapshot.Arguments = []string{"test", dir + "/data/file1"}
+	c.Assert(Snapshot(snapshot), ErrorMatches, `Can't create snapshot of .*/file1: object is not a directory`)
+	snapshot.Arguments = []string{"test", dir + "/unknown"}
+	c.Assert(Snapshot(snapshot), ErrorMatches, `Can't create snapshot of .*/unknown: .*`)
+}
+
+func (s *ActionSuite) TestChangeTracker(c *C) {
+	dir := c.MkDir()
+	tmpDir := c.MkDir()
+
+	c.Assert(os.Mkdir(dir+"/data", 0750), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file", []byte("test"), 0640), IsNil)
+	c.Assert(os.WriteFile(dir+"/meta", []byte("meta"), 0600), IsNil)
+
+	t := NewChangeTracker(tmpDir)
+
+	c.Assert(t.Track(dir+"/data", true), IsNil)
+	c.Assert(t.Track(dir+"/data/file", true), IsNil)
+	c.Assert(t.Track(dir+"/meta", false), IsNil)
+	c.Assert(t.Track(dir+"/new/dir/file", true), IsNil)
+	c.Assert(t.changes, HasLen, 3)
+
+	c.Assert(os.WriteFile(dir+"/data/file", []byte("changed"), 0644), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file1", []byte("new"), 0644), IsNil)
+	c.Assert(os.Chmod(dir+"/meta", 0644), IsNil)
+	c.Assert(os.MkdirAll(dir+"/new/dir", 0755), IsNil)
+
+	report := t.Restore()
+
+	c.Assert(report, DeepEquals, []string{
+		"Removed " + dir + "/new (created during tests)",
+		fmt.Sprintf(
+			"Restored mode and owner of %s/meta (-rw------- %d:%d)",
+			dir, os.Getuid(), os.Getgid(),
+		),
+		"Restored " + dir + "/data",
+	})
+
+	data, err := os.ReadFile(dir + "/data/file")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "test")
+
+	c.Assert(fsutil.IsExist(dir+"/data/file1"), Equals, false)
+	c.Assert(fsutil.IsExist(dir+"/new"), Equals, false)
+
+	info, err := os.Stat(dir + "/meta")
+	c.Assert(err, IsNil)
+	c.Assert(info.Mode(), Equals, os.FileMode(0600))
+
+	c.Assert(t.Restore(), IsNil)
+
+	var nilTracker *ChangeTracker
+
+	c.Assert(nilTracker.Track(dir+"/data", true), IsNil)
+	c.Assert(nilTracker.Restore(), IsNil)
+}
+
+func (s *ActionSuite) TestChangeTrackerFlags(c *C) {
+	dir := c.MkDir()
+	tmpDir := c.MkDir()
+
+	c.Assert(os.WriteFile(dir+"/meta", []byte("meta"), 0600), IsNil)
+	c.Assert(os.Mkdir(dir+"/data", 0750), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file", []byte("test"), 0640), IsNil)
+
+	metaFlags, err := getFileFlags(dir + "/meta")
+
+	if err != nil {
+		c.Skip("Filesystem doesn't support inode flags")
+	}
+
+	fileFlags, err := getFileFlags(dir + "/data/file")
+	c.Assert(err, IsNil)
+
+	t := NewChangeTracker(tmpDir)
+
+	c.Assert(t.Track(dir+"/meta", false), IsNil)
+	c.Assert(t.Track(dir+"/data", true), IsNil)
+
+	c.Assert(setFileFlags(dir+"/meta", metaFlags|fileAttrs["no-dump"]), IsNil)
+	c.Assert(setFileFlags(dir+"/data/file", fileFlags|fileAttrs["no-dump"]), IsNil)
+
+	if os.Geteuid() == 0 {
+		c.Assert(setFileFlags(dir+"/data/file", fileFlags|fileAttrs["immutable"]), IsNil)
+	}
+
+	c.Assert(t.Restore(), DeepEquals, []string{
+		"Restored " + dir + "/data",
+		"Restored file attributes of " + dir + "/meta",
+	})
+
+	flags, err := getFileFlags(dir + "/meta")
+	c.Assert(err, IsNil)
+	c.Assert(flags, Equals, metaFlags)
+
+	flags, err = getFileFlags(dir + "/data/file")
+	c.Assert(err, IsNil)
+	c.Assert(flags, Equals, fileFlags)
+}
+
+func (s *ActionSuite) TestBackup(c *C) {
+	dir := c.MkDir()
+	tmpDir := c.MkDir()
+
+	c.Assert(os.Mkdir(dir+"/data", 0750), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file", []byte("test"), 0640), IsNil)
+	c.Assert(os.WriteFile(dir+"/file", []byte("test"), 0640), IsNil)
+
+	r := recipe.NewRecipe(dir + "/test.recipe")
+	r.Dir = dir
+
+	cmd := recipe.NewCommand([]string{"echo"}, 1)
+	c.Assert(r.AddCommand(cmd, "", false), IsNil)
+
+	backup := &recipe.Action{Name: "backup", Arguments: []string{dir + "/data"}}
+	restore := &recipe.Action{Name: "backup-restore", Arguments: []string{dir + "/data"}}
+
+	c.Assert(cmd.AddAction(backup), IsNil)
+	c.Assert(cmd.AddAction(restore), IsNil)
+
+	c.Assert(Backup(backup, tmpDir), IsNil)
+	c.Assert(getBackups(r), HasLen, 1)
+
+	c.Assert(os.WriteFile(dir+"/data/file", []byte("changed"), 0644), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file1", []byte("new"), 0644), IsNil)
+
+	c.Assert(BackupRestore(restore), IsNil)
+
+	data, err := os.ReadFile(dir + "/data/file")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "test")
+	c.Assert(fsutil.IsExist(dir+"/data/file1"), Equals, false)
+
+	// Object restored by action must not be restored again
+	c.Assert(os.RemoveAll(dir+"/data"), IsNil)
+	c.Assert(RestoreBackups(r), HasLen, 0)
+	c.Assert(fsutil.IsExist(dir+"/data"), Equals, false)
+
+	c.Assert(os.Mkdir(dir+"/data", 0750), IsNil)
+	c.Assert(os.WriteFile(dir+"/data/file", []byte("test"), 0640), IsNil)
+	c.Assert(Backup(backup, tmpDir), IsNil)
+	c.Assert(os.RemoveAll(dir+"/data"), IsNil)
+	c.Assert(RestoreBackups(r), HasLen, 0)
+	c.Assert(fsutil.IsExist(dir+"/data/file"), Equals, true)
+
+	backup.Arguments = []string{dir + "/unknown"}
+	c.Assert(Backup(backup, tmpDir), ErrorMatches, `Object .*/unknown does not exist`)
+
+	backup.Arguments = []string{dir + "/file/unknown"}
+	c.Assert(Backup(backup, tmpDir), ErrorMatches, `Can't backup .*/file/unknown: .* not a directory`)
+
+	backup.Arguments = []string{"/etc"}
+	c.Assert(Backup(backup, tmpDir), ErrorMatches, `Path is unsafe \(/etc\)`)
+
+	restore.Arguments = []string{dir + "/file"}
+	c.Assert(BackupRestore(restore), ErrorMatches, `Backup for .*/file does not exist`)
+}
+
+func (s *ActionSuite) TestACL(c *C) {
+	// user::rwx user:12345:rwx group::r-x group:12345:rw- mask::r-x other::r--
+	data := []byte{
+		0x02, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x07, 0x00, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x02, 0x00, 0x07, 0x00, 0x39, 0x30, 0x00, 0x00,
+		0x04, 0x00, 0x05, 0x00, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x08, 0x00, 0x06, 0x00, 0x39, 0x30, 0x00, 0x00,
+		0x10, 0x00, 0x05, 0x00, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x20, 0x00, 0x04, 0x00, 0xFF, 0xFF, 0xFF, 0xFF,
+	}
+
+	acl, err := parseACL(data)
+
+	c.Assert(err, IsNil)
+	c.Assert(acl, DeepEquals, []aclEntry{
+		{Tag: aclTagUserObj, Perm: 7, ID: aclUndefID},
+		{Tag: aclTagUser, Perm: 7, ID: 12345},
+		{Tag: aclTagGroupObj, Perm: 5, ID: aclUndefID},
+		{Tag: aclTagGroup, Perm: 6, ID: 12345},
+		{Tag: aclTagMask, Perm: 5, ID: aclUndefID},
+		{Tag: aclTagOther, Perm: 4, ID: aclUndefID},
+	})
+
+	_, err = parseACL(nil)
+	c.Assert(err, ErrorMatches, "unsupported format")
+	_, err = parseACL(data[:10])
+	c.Assert(err, ErrorMatches, "unsupported format")
+	_, err = parseACL(append([]byte{0x01, 0x00, 0x00, 0x00}, data[4:]...))
+	c.Assert(err, ErrorMatches, "unsupported format")
+
+	specCases := []struct {
+		entry string
+		spec  *aclEntrySpec
+		err   string
+	}{
+		{"user::rwx", &aclEntrySpec{Raw: "user::rwx", Tag: aclTagUserObj, Perm: 7, ID: aclUndefID, HasPerm: true}, ""},
+		{"u:12345:r-x", &aclEntrySpec{Raw: "u:12345:r-x", Tag: aclTagUser, Perm: 5, ID: 12345, HasPerm: true}, ""},
+		{"u:root", &aclEntrySpec{Raw: "u:root", Tag: aclTagUser, ID: 0}, ""},
+		{"group::---", &aclEntrySpec{Raw: "group::---", Tag: aclTagGroupObj, Perm: 0, ID: aclUndefID, HasPerm: true}, ""},
+		{"g:12345", &aclEntrySpec{Raw: "g:12345", Tag: aclTagGroup, ID: 12345}, ""},
+		{"mask::rw", &aclEntrySpec{Raw: "mask::rw", Tag: aclTagMask, Perm: 6, ID: aclUndefID, HasPerm: true}, ""},
+		{"o::r", &aclEntrySpec{Raw: "o::r", Tag: aclTagOther, Perm: 4, ID: aclUndefID, HasPerm: true}, ""},
+		{"user", nil, `Invalid ACL entry "user"`},
+		{"user::rwx:1", nil, `Invalid ACL entry "user::rwx:1"`},
+		{"test::rwx", nil, `Invalid ACL entry "test::rwx": unknown entry type "test"`},
+		{"mask:root:rwx", nil, `Invalid ACL entry "mask:root:rwx": mask entry can't have qualifier`},
+		{"user::", nil, `Invalid ACL entry "user::": permissions are empty`},
+		{"user::rwz", nil, `Invalid ACL entry "user::rwz": unknown permission 'z'`},
+		{"user:_unknown_user_:rwx", nil, `User _unknown_user_ doesn't exist on the system`},
+		{"group:_unknown_group_:rwx", nil, `Group _unknown_group_ doesn't exist on the system`},
+	}
+
+	for _, tc := range specCases {
+		spec, err := parseACLEntrySpec(tc.entry)
+
+		if tc.err != "" {
+			c.Assert(err, NotNil, Commentf(tc.entry))
+			c.Assert(err.Error(), Equals, tc.err, Commentf(tc.entry))
+			continue
+		}
+
+		c.Assert(err, IsNil, Commentf(tc.entry))
+		c.Assert(spec, DeepEquals, tc.spec, Commentf(tc.entry))
+	}
+
+	matchCases := []struct {
+		entry string
+		match bool
+	}{
+		{"user::rwx", true},
+		{"user:12345", true},
+		{"user:12345:r-x", true}, // effective permissions
+		{"user:12345:rwx", false},
+		{"group::r-x", true},
+		{"group:12345:r--", true}, // effective permissions
+		{"group:12345:rw-", false},
+		{"mask::r-x", true},
+		{"other::r--", true},
+		{"other::rwx", false},
+		{"user:0", false},
+	}
+
+	for _, tc := range matchCases {
+		spec, err := parseACLEntrySpec(tc.entry)
+		c.Assert(err, IsNil, Commentf(tc.entry))
+		c.Assert(spec.IsMatch(acl), Equals, tc.match, Commentf(tc.entry))
+	}
+
+	c.Assert(formatACL("/tmp/test", acl, false), Equals, strings.Join([]string{
+		"# file: /tmp/test",
+		"user::rwx",
+		"user:12345:rwx\t#effective:r-x",
+		"group::r-x",
+		"group:12345:rw-\t#effective:r--",
+		"mask::r-x",
+		"other::r--",
+	}, "\n"))
+
+	c.Assert(formatACL("/tmp/test", acl[:1], true), Equals, "# file: /tmp/test\ndefault:user::rwx")
+	c.Assert(formatACL("/tmp/test", nil, false), Equals, "# file: /tmp/test\n# ACL is empty")
+}
+
+func (s *ActionSuite) TestUnitName(c *C) {
+	nameCases := []struct {
+		unit   string
+		result string
+	}{
+		{"nginx", "nginx.service"},
+		{"nginx.service", "nginx.service"},
+		{"nginx.socket", "nginx.socket"},
+		{"backup.timer", "backup.timer"},
+		{"dev-sda.device", "dev-sda.device"},
+		{"php-fpm7.4", "php-fpm7.4.service"},
+		{"java-1.8.0", "java-1.8.0.service"},
+		{"worker@1", "worker@1.service"},
+		{"worker@1.path", "worker@1.path"},
+	}
+
+	for _, tc := range nameCases {
+		c.Assert(getUnitName(tc.unit), Equals, tc.result, Commentf(tc.unit))
+	}
+
+	propCases := []struct {
+		unit     string
+		property string
+		section  string
+		key      string
+	}{
+		{"nginx.service", "User", "Service", "User"},
+		{"nginx.service", "Unit.After", "Unit", "After"},
+		{"nginx.socket", "ListenStream", "Socket", "ListenStream"},
+		{"backup.timer", "OnCalendar", "Timer", "OnCalendar"},
+		{"multi-user.target", "Description", "Unit", "Description"},
+		{"backup.timer", "Install.WantedBy", "Install", "WantedBy"},
+	}
+
+	for _, tc := range propCases {
+		section, key := parseUnitPropertyName(tc.unit, tc.property)
+		c.Assert(section, Equals, tc.section, Commentf(tc.property))
+		c.Assert(key, Equals, tc.key, Commentf(tc.property))
+	}
+}
+
+func (s *ActionSuite) TestUnitProperty(c *C) {
+	etcDir, libDir := c.MkDir(), c.MkDir()
+
+	origUnitDirs := unitDirs
+	unitDirs = []string{etcDir, libDir}
+	defer func() { unitDirs = origUnitDirs }()
+
+	files := map[string]string{
+		libDir + "/myapp.service": "[Unit]\n" +
+			"Description=My App\n" +
+			"After=network.target\n" +
+			"Restart=no\n\n" +
+			"[Service]\n" +
+			"# User=root\n" +
+			"; Group=root\n" +
+			"User=myapp\n" +
+			"ExecStart=/usr/bin/myapp \\\n  --config /etc/myapp.conf\n" +
+			"Environment=A=1\n" +
+			"Environment=B=2\n" +
+			"Restart = on-failure\n\n" +
+			"[Install]\n" +
+			"WantedBy=multi-user.target\n",
+		libDir + "/myapp.service.d/10-user.conf":    "[Service]\nUser=nobody\n",
+		libDir + "/myapp.service.d/20-env.conf":     "[Service]\nEnvironment=C=3\n",
+		etcDir + "/myapp.service.d/10-user.conf":    "[Service]\nUser=override\n",
+		etcDir + "/myapp.service.d/30-unit.conf":    "[Unit]\nAfter=\nAfter=remote-fs.target\n",
+		libDir + "/myapp.socket":                    "[Socket]\nListenStream=/run/myapp.sock\n\n[Install]\nWantedBy=sockets.target\n",
+		libDir + "/worker@.service":                 "[Service]\nExecStart=/usr/bin/worker %i\n",
+		libDir + "/worker@.service.d/10-nice.conf":  "[Service]\nNice=10\n",
+		etcDir + "/worker@1.service.d/10-nice.conf": "[Service]\nNice=5\n",
+	}
+
+	for file, data := range files {
+		c.Assert(os.MkdirAll(filepath.Dir(file), 0755), IsNil)
+		c.Assert(os.WriteFile(file, []byte(data), 0644), IsNil)
+	}
+
+	c.Assert(findUnitFiles("myapp.service"), DeepEquals, []string{
+		libDir + "/myapp.service",
+		etcDir + "/myapp.service.d/10-user.conf",
+		libDir + "/myapp.service.d/20-env.conf",
+		etcDir + "/myapp.service.d/30-unit.conf",
+	})
+
+	c.Assert(findUnitFiles("worker@1.service"), DeepEquals, []string{
+		libDir + "/worker@.service",
+		etcDir + "/worker@1.service.d/10-nice.conf",
+	})
+
+	c.Assert(findUnitFiles("unknown.service"), IsNil)
+
+	propCases := []struct {
+		unit     string
+		property string
+		values   []string
+	}{
+		{"myapp.service", "User", []string{"myapp", "override"}},
+		{"myapp.service", "Service.User", []string{"myapp", "override"}},
+		{"myapp.service", "Unit.User", nil},
+		{"myapp.service", "Restart", []string{"on-failure"}},
+		{"myapp.service", "Unit.Restart", []string{"no"}},
+		{"myapp.service", "ExecStart", []string{"/usr/bin/myapp    --config /etc/myapp.conf"}},
+		{"myapp.service", "Environment", []string{"A=1", "B=2", "C=3"}},
+		{"myapp.service", "Unit.After", []string{"remote-fs.target"}},
+		{"myapp.service", "Unit.Description", []string{"My App"}},
+		{"myapp.service", "Install.WantedBy", []string{"multi-user.target"}},
+		{"myapp.service", "Group", nil},
+		{"myapp.socket", "ListenStream", []string{"/run/myapp.sock"}},
+		{"myapp.socket", "Install.WantedBy", []string{"sockets.target"}},
+		{"worker@1.service", "ExecStart", []string{"/usr/bin/worker %i"}},
+		{"worker@1.service", "Nice", []string{"5"}},
+		{"worker@2.service", "Nice", []string{"10"}},
+	}
+
+	for _, tc := range propCases {
+		values, err := readUnitProperty(tc.unit, tc.property)
+		c.Assert(err, IsNil, Commentf("%s %s", tc.unit, tc.property))
+		c.Assert(values, DeepEquals, tc.values, Commentf("%s %s", tc.unit, tc.property))
+	}
+
+	_, err := readUnitProperty("unknown.service", "User")
+	c.Assert(err, ErrorMatches, "Can't find unit file for unit unknown.service")
+}
+
+func (s *ActionSuite) TestLogFollower(c *C) {
+	file := c.MkDir() + "/app.log"
+
+	c.Assert(os.WriteFile(file, []byte("old record 1\nold record 2\n"), 0644), IsNil)
+
+	f := newLogFollower(file)
+	defer f.Close()
+
+	c.Assert(f.Read(), IsNil)
+	c.Assert(string(f.Data()), Equals, "")
+
+	// Append
+	appendLog(c, file, "record 1\n")
+	c.Assert(f.Read(), IsNil)
+	c.Assert(string(f.Data()), Equals, "record 1\n")
+
+	appendLog(c, file, "record 2\n")
+	c.Assert(f.Read(), IsNil)
+	c.Assert(string(f.Data()), Equals, "record 1\nrecord 2\n")
+
+	// Truncate
+	c.Assert(os.Truncate(file, 0), IsNil)
+	appendLog(c, file, "record 3\n")
+	c.Assert(f.Read(), IsNil)
+	c.Assert(string(f.Data()), Equals, "record 1\nrecord 2\nrecord 3\n")
+
+	// Rotate by rename
+	c.Assert(os.Rename(file, file+".1"), IsNil)
+	appendLog(c, file+".1", "record 4\n")
+	appendLog(c, file, "record 5\n")
+	c.Assert(f.Read(), IsNil)
+	c.Assert(string(f.Data()), Equals, "record 1\nrecord 2\nrecord 3\nrecord 4\nrecord 5\n")
+
+	appendLog(c, file, "record 6\n")
+	c.Assert(f.Read(), IsNil)
+	c.Assert(string(f.Data()), Equals, "record 1\nrecord 2\nrecord 3\nrecord 4\nrecord 5\nrecord 6\n")
+
+	// Remove
+	c.Assert(os.Remove(file), IsNil)
+	c.Assert(f.Read(), IsNil)
+
+	// Log created after command start
+	appendLog(c, file, "record 7\n")
+	c.Assert(f.Read(), IsNil)
+	c.Assert(string(f.Data()), Equals, "record 1\nrecord 2\nrecord 3\nrecord 4\nrecord 5\nrecord 6\nrecord 7\n")
+
+	// Log doesn't exist
+	f = newLogFollower(c.MkDir() + "/new.log")
+	c.Assert(f.Read(), IsNil)
+	c.Assert(string(f.Data()), Equals, "")
+}
+
+func (s *ActionSuite) TestWaitFS(c *C) {
+	dir := c.MkDir()
+
+	c.Assert(os.WriteFile(dir+"/file", []byte("test"), 0640), IsNil)
+
+	cmd := recipe.NewCommand([]string{"echo"}, 1)
+	modify := &recipe.Action{Name: "wait-fs-modify", Arguments: []string{dir + "/file", "0.1"}}
+	settle := &recipe.Action{Name: "wait-fs-settle", Arguments: []string{dir + "/unknown", "50", "0.3"}}
+
+	c.Assert(cmd.AddAction(modify), IsNil)
+	c.Assert(cmd.AddAction(settle), IsNil)
+
+	SaveFSState(cmd)
+
+	c.Assert(WaitFSModify(modify), ErrorMatches, `Timeout \(0.1 sec\) reached, and .*/file wasn't modified`)
+
+	c.Assert(os.WriteFile(dir+"/file", []byte("test1"), 0640), IsNil)
+	c.Assert(WaitFSModify(modify), IsNil)
+
+	start := time.Now()
+
+	c.Assert(WaitFSSettle(settle), ErrorMatches, `Timeout \(0.3 sec\) reached, and .*/unknown didn't settle for 50 ms`)
+	c.Assert(time.Since(start) >= 300*time.Millisecond, Equals, true)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		os.WriteFile(dir+"/unknown", []byte("test"), 0640)
+	}()
+
+	c.Assert(WaitFSSettle(settle), IsNil)
+}
+
+func (s *ActionSuite) TestReadCgroupUsage(c *C) {
+	dir := c.MkDir()
+
+	c.Assert(os.WriteFile(dir+"/memory.peak", []byte("52428800\n"), 0644), IsNil)
+	c.Assert(os.WriteFile(dir+"/cpu.stat", []byte(
+		"usage_usec 1500000\nuser_usec 1000000\nsystem_usec 500000\n"+
+			"nr_periods 0\nnr_throttled 0\nthrottled_usec 0\n",
+	), 0644), IsNil)
+	c.Assert(os.WriteFile(dir+"/io.stat", []byte(
+		"8:0 rbytes=4096 wbytes=1024 rios=1 wios=1 dbytes=0 dios=0\n"+
+			"253:0 rbytes=8192 wbytes=2048 rios=2 wios=2 dbytes=0 dios=0\n",
+	), 0644), IsNil)
+
+	usage := &recipe.ResourceUsage{MaxRSS: 1024, IORead: 1, IOWrite: 1}
+	readCgroupUsage(dir, usage)
+
+	c.Assert(usage, DeepEquals, &recipe.ResourceUsage{
+		MaxRSS:     52428800,
+		UserTime:   time.Second,
+		SystemTime: 500 * time.Millisecond,
+		IORead:     12288,
+		IOWrite:    3072,
+	})
+
+	usage = &recipe.ResourceUsage{MaxRSS: 1024, IORead: 1, IOWrite: 1}
+	readCgroupUsage(dir+"/unknown", usage)
+
+	c.Assert(usage, DeepEquals, &recipe.ResourceUsage{MaxRSS: 1024, IORead: 1, IOWrite: 1})
+}
+
+func (s *ActionSuite) TestParseSocketInfo(c *C) {
+	inetCases := []struct {
+		file   string
+		line   string
+		result *socketInfo
+	}{
+		{
+			"tcp", "   2: 0100007F:1F90 00000000:0000 0A 00000000:00000001 00:00000000 00000000     0        0 128818 2 000000000b248fa6 100 0 0 10 0",
+			&socketInfo{Proto: "tcp", IP: net.ParseIP("127.0.0.1").To4(), Port: 8080, Inode: "128818"},
+		},
+		{
+			"tcp", "   0: 00000000:07E8 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 662 1 00000000aae46570 100 0 0 10 0",
+			&socketInfo{Proto: "tcp", IP: net.IPv4zero.To4(), Port: 2024, Inode: "662"},
+		},
+		{
+			"tcp6", "   0: 00000000000000000000000001000000:20FB 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 128817 1 000000009dd4e2a9 100 0 0 10 0",
+			&socketInfo{Proto: "tcp6", IP: net.ParseIP("::1"), Port: 8443, Inode: "128817"},
+		},
+		{
+			"tcp6", "   1: 0000000000000000FFFF00000100007F:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 23456 1 000000009dd4e2a9 100 0 0 10 0",
+			&socketInfo{Proto: "tcp6", IP: net.ParseIP("::ffff:127.0.0.1"), Port: 80, Inode: "23456"},
+		},
+		{
+			"tcp6", "   2: B80D0120000000000000000001000000:01BB 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 34567 1 000000009dd4e2a9 100 0 0 10 0",
+			&socketInfo{Proto: "tcp6", IP: net.ParseIP("2001:db8::1"), Port: 443, Inode: "34567"},
+		},
+		{
+			"udp", " 3920: 00000000:14E9 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 128822 2 000000008d1b489e 0",
+			&socketInfo{Proto: "udp", IP: net.IPv4zero.To4(), Port: 5353, Inode: "128822"},
+		},
+		{ // Established TCP connection
+			"tcp", "   6: 0100007F:B51E 0100007F:1F90 01 00000000:00000000 00:00000000 00000000     0        0 128821 2 000000000ca663ac 20 0 0 10 -1",
+			nil,
+		},
+		{ // Connected UDP socket
+			"udp", " 1234: 0100007F:14E9 0100007F:0035 01 00000000:00000000 00:00000000 00000000     0        0 4321 2 000000008d1b489e 0",
+			nil,
+		},
+		{"tcp", "   0: 0100007F:1F90 00000000:0000 0A", nil},
+		{"tcp", "   0: 0100007F 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1", nil},
+		{"tcp", "   0: 0100ZZ7F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1", nil},
+		{"tcp", "   0: 0100007F:ZZZZ 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1", nil},
+	}
+
+	for _, tc := range inetCases {
+		c.Assert(parseInetSocketInfo(tc.file, strings.Fields(tc.line)), DeepEquals, tc.result, Commentf(tc.line))
+	}
+
+	unixCases := []struct {
+		line   string
+		result *socketInfo
+	}{
+		{
+			"00000000a4575b4f: 00000002 00000000 00010000 0001 01 128819 /tmp/t.sock",
+			&socketInfo{Proto: "unix", Path: "/tmp/t.sock", Inode: "128819"},
+		},
+		{
+			"0000000053687c46: 00000002 00000000 00000000 0002 01 128820 /tmp/d.sock",
+			&socketInfo{Proto: "unix", Path: "/tmp/d.sock", Inode: "128820"},
+		},
+		{ // Abstract socket
+			"00000000e4b2b8f3: 00000002 00000000 00010000 0001 01 23140 @/tmp/.X11-unix/X0",
+			&socketInfo{Proto: "unix", Path: "@/tmp/.X11-unix/X0", Inode: "23140"},
+		},
+		{ // Connected stream socket
+			"000000004c3ad11f: 00000003 00000000 00000000 0001 03 35114 /run/systemd/journal/stdout",
+			nil,
+		},
+		{ // Unnamed socket
+			"00000000c8f5f4a6: 00000003 00000000 00000000 0001 03 35113",
+			nil,
+		},
+		{"00000000a4575b4f: 00000002 00000000 0001ZZ00 0001 01 128819 /tmp/t.sock", nil},
+	}
+
+	for _, tc := range unixCases {
+		c.Assert(parseUnixSocketInfo(strings.Fields(tc.line)), DeepEquals, tc.result, Commentf(tc.line))
+	}
+
+	c.Assert(parseHexIP("0100007F"), DeepEquals, net.IP{127, 0, 0, 1})
+	c.Assert(parseHexIP("0100007F00"), IsNil)
+	c.Assert(parseHexIP("XX00007F"), IsNil)
+}
+
+func (s *ActionSuite) TestGetCommandPID(c *C) {
+	action := &recipe.Action{Name: "proc-fds"}
+
+	_, err := getTargetPID(action, 0, nil)
+	c.Assert(err, ErrorMatches, `PID file is required .*`)
+
+	cmd := exec.Command("stdbuf", "-o0", "sleep", "5")
+	c.Assert(cmd.Start(), IsNil)
+
+	pid, err := getTargetPID(action, 0, cmd)
+	c.Assert(err, IsNil)
+	c.Assert(pid, Equals, cmd.Process.Pid)
+	c.Assert(getProcArgs(pid), DeepEquals, []string{"sleep", "5"})
+
+	cmd.Process.Kill()
+	cmd.Wait()
+
+	if os.Geteuid() != 0 {
+		c.Skip("Running commands with runuser requires root privileges")
+	}
+
+	cmd = exec.Command("/sbin/runuser", "-s", "/bin/bash", "nobody", "-c", "sleep 5; true")
+	c.Assert(cmd.Start(), IsNil)
+
+	pid, err = getTargetPID(action, 0, cmd)
+	c.Assert(err, IsNil)
+	c.Assert(pid, Not(Equals), cmd.Process.Pid)
+	c.Assert(getProcArgs(pid), DeepEquals, []string{"sleep", "5"})
+
+	cmd.Process.Kill()
+	cmd.Wait()
+	syscall.Kill(pid, syscall.SIGKILL)
+}
+
+func (s *ActionSuite) TestExitWrapped(c *C) {
+	cmd := recipe.NewCommand([]string{"nobody:echo"}, 1)
+	cmd.User = "nobody"
+
+	proc := exec.Command("/bin/sh", "-c", "exit 143")
+	c.Assert(proc.Run(), NotNil)
+
+	testCases := []struct {
+		cond     string
+		negative bool
+		err      string
+	}{
+		{"SIGTERM", false, ""},
+		{"143", false, ""},
+		{"SIGINT,SIGTERM", false, ""},
+		{"0,SIGTERM", false, ""},
+		{"SIGKILL", false, `The process was killed by invalid signal \(SIGTERM ≠ SIGKILL\)`},
+		{"0", false, `The process has exited with invalid exit code \(143 ≠ 0\)`},
+		{"SIGTERM", true, "The process was killed by signal SIGTERM"},
+		{"SIGKILL", true, ""},
+		{"1", true, ""},
+	}
+
+	for _, tc := range testCases {
+		a := &recipe.Action{Name: "exit", Arguments: []string{tc.cond}, Negative: tc.negative}
+		c.Assert(cmd.AddAction(a), IsNil)
+
+		err := Exit(a, proc)
+
+		if tc.err == "" {
+			c.Assert(err, IsNil, Commentf(tc.cond))
+		} else {
+			c.Assert(err, ErrorMatches, tc.err, Commentf(tc.cond))
+		}
+	}
+
+	// Without user exit code is not treated as signal
+	cmd.User = ""
+	a := &recipe.Action{Name: "exit", Arguments: []string{"SIGTERM"}}
+	c.Assert(cmd.AddAction(a), IsNil)
+	c.Assert(Exit(a, proc), ErrorMatches, "The process has exited with code 143 instead of being killed by signal SIGTERM")
+}
+
+func (s *ActionSuite) TestGetWrappedCrashInfo(c *C) {
+	cmd := exec.Command("/bin/sh", "-c", "exit 139")
+	c.Assert(cmd.Run(), NotNil)
+
+	c.Assert(GetCrashInfo(cmd), IsNil)
+	c.Assert(GetWrappedCrashInfo(cmd), DeepEquals, &recipe.CrashInfo{Signal: "SIGSEGV"})
+
+	cmd = exec.Command("/bin/sh", "-c", "exit 137")
+	c.Assert(cmd.Run(), NotNil)
+	c.Assert(GetWrappedCrashInfo(cmd), IsNil)
+
+	cmd = exec.Command("/bin/sh", "-c", "kill -ABRT $$")
+	c.Assert(cmd.Run(), NotNil)
+	c.Assert(GetWrappedCrashInfo(cmd), NotNil)
+	c.Assert(GetWrappedCrashInfo(cmd).Signal, Equals, "SIGABRT")
+
+	c.Assert(GetWrappedCrashInfo(nil), IsNil)
+}
+
+func (s *ActionSuite) TestParseExitCondition(c *C) {
+	testCases := []struct {
+		Value   string
+		Codes   [][2]int
+		Signals []syscall.Signal
+	}{
+		{"0", [][2]int{{0, 0}}, nil},
+		{"0,3", [][2]int{{0, 0}, {3, 3}}, nil},
+		{"0, 3 ,5", [][2]int{{0, 0}, {3, 3}, {5, 5}}, nil},
+		{"64-78", [][2]int{{64, 78}}, nil},
+		{"1-1", [][2]int{{1, 1}}, nil},
+		{"0,64-78", [][2]int{{0, 0}, {64, 78}}, nil},
+		{"EX_USAGE", [][2]int{{64, 64}}, nil},
+		{"ex_config", [][2]int{{78, 78}}, nil},
+		{"EX_OK,EX_TEMPFAIL", [][2]int{{0, 0}, {75, 75}}, nil},
+		{"SIGTERM", nil, []syscall.Signal{syscall.SIGTERM}},
+		{"SIGSEGV,SIGABRT", nil, []syscall.Signal{syscall.SIGSEGV, syscall.SIGABRT}},
+		{"0,SIGTERM", [][2]int{{0, 0}}, []syscall.Signal{syscall.SIGTERM}},
+	}
+
+	for _, tc := range testCases {
+		cond, err := parseExitCondition(tc.Value)
+
+		c.Assert(err, IsNil, Commentf("Value: %q", tc.Value))
+		c.Assert(cond.Codes, DeepEquals, tc.Codes, Commentf("Value: %q", tc.Value))
+		c.Assert(cond.Signals, DeepEquals, tc.Signals, Commentf("Value: %q", tc.Value))
+	}
+
+	for _, v := range []string{"", "0,", "78-64", "1-", "-1", "1-2-3", "EX_UNKNOWN", "SIGUNKNOWN", "abc"} {
+		_, err := parseExitCondition(v)
+		c.Assert(err, NotNil, Commentf("Value: %q", v))
+	}
+
+	_, err := parseExitCondition("78-64")
+	c.Assert(err, ErrorMatches, `Invalid range of exit codes "78-64"`)
+
+	_, err = parseExitCondition("0,abc")
+	c.Assert(err, ErrorMatches, `Invalid exit code or signal "abc"`)
+
+	cond, err := parseExitCondition("0,64-78,SIGTERM")
+	c.Assert(err, IsNil)
+
+	c.Assert(cond.IsMatch(syscall.WaitStatus(0)), Equals, true)
+	c.Assert(cond.IsMatch(syscall.WaitStatus(70<<8)), Equals, true)
+	c.Assert(cond.IsMatch(syscall.WaitStatus(1<<8)), Equals, false)
+	c.Assert(cond.IsMatch(syscall.WaitStatus(79<<8)), Equals, false)
+	c.Assert(cond.IsMatch(syscall.WaitStatus(syscall.SIGTERM)), Equals, true)
+	c.Assert(cond.IsMatch(syscall.WaitStatus(syscall.SIGKILL)), Equals, false)
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+func appendLog(c *C, file, data string) {
+	fd, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+
+	c.Assert(err, IsNil)
+
+	_, err = fd.WriteString(data)
+
+	c.Assert(err, IsNil)
+	c.Assert(fd.Close(), IsNil)
+}
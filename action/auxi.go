@@ -9,9 +9,11 @@ package action
 
 import (
 	"bytes"
+	"errors"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/essentialkaos/bibop/recipe"
 	"github.com/essentialkaos/ek/v13/strutil"
@@ -30,6 +32,9 @@ type OutputContainer struct {
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
+// ErrAborted is returned by actions if command execution was aborted
+var ErrAborted = errors.New("Command execution aborted")
+
 // escapeCharRegex is regexp for searching escape characters
 var escapeCharRegex = regexp.MustCompile("[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))")
 
@@ -146,6 +151,39 @@ func checkPathSafety(r *recipe.Recipe, path string) (bool, error) {
 	return strings.HasPrefix(targetPath, workingDir), nil
 }
 
+// isAborted returns true if execution of action command was aborted
+func isAborted(action *recipe.Action) bool {
+	if action.Command == nil {
+		return false
+	}
+
+	select {
+	case <-action.Command.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep pauses action for given duration and returns false if execution of
+// action command was aborted
+func sleep(action *recipe.Action, dur time.Duration) bool {
+	if action.Command == nil {
+		time.Sleep(dur)
+		return true
+	}
+
+	timer := time.NewTimer(dur)
+	defer timer.Stop()
+
+	select {
+	case <-action.Command.Aborted:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 // fmtValue formats value
 func fmtValue(v string) string {
 	if v == "" {
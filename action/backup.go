@@ -8,17 +8,33 @@ package action
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 import (
+	"crypto/sha256"
 	"fmt"
-	"hash/crc32"
 	"os"
+	"path/filepath"
 
-	"github.com/essentialkaos/ek/v13/fsutil"
+	"github.com/essentialkaos/ek/v13/hashutil"
 
 	"github.com/essentialkaos/bibop/recipe"
 )
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
+// PROP_BACKUPS is name of property with info about all backups
+const PROP_BACKUPS = "BACKUPS"
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// backupInfo contains info about backup of filesystem object
+type backupInfo struct {
+	Path       string     // Path to original object
+	Backup     string     // Path to object copy
+	Xattrs     treeXattrs // Extended attributes of all objects
+	IsRestored bool       // Object was restored by "backup-restore" action
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
 // Backup is action processor for "backup"
 func Backup(action *recipe.Action, tmpDir string) error {
 	path, err := action.GetS(0)
@@ -33,34 +49,57 @@ func Backup(action *recipe.Action, tmpDir string) error {
 		return err
 	}
 
-	switch {
-	case !isSafePath:
+	if !isSafePath {
 		return fmt.Errorf("Path is unsafe (%s)", path)
-	case !fsutil.IsExist(path):
-		return fmt.Errorf("File %s does not exist", path)
-	case !fsutil.IsRegular(path):
-		return fmt.Errorf("Object %s is not a file", path)
 	}
 
-	pathCRC32 := calcCRC32Q(path)
+	path, err = filepath.Abs(path)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Lstat(path)
+
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Errorf("Object %s does not exist", path)
+	case err != nil:
+		return fmt.Errorf("Can't backup %s: %v", path, err)
+	}
+
+	info := &backupInfo{
+		Path:   path,
+		Backup: tmpDir + "/" + hashutil.String(path, sha256.New()).String(),
+	}
+
+	err = os.RemoveAll(info.Backup)
+
+	if err != nil {
+		return fmt.Errorf("Can't remove previous backup: %v", err)
+	}
 
-	err = fsutil.CopyFile(path, tmpDir+"/"+pathCRC32)
+	err = copyObject(info.Path, info.Backup)
 
 	if err != nil {
-		return fmt.Errorf("Can't backup file: %v", err)
+		return fmt.Errorf("Can't backup %s: %v", path, err)
 	}
 
-	err = fsutil.CopyAttr(path, tmpDir+"/"+pathCRC32)
+	// Attributes are stored in memory because filesystem with temporary
+	// data may not support all of them
+	info.Xattrs, err = getTreeXattrs(info.Path)
 
 	if err != nil {
-		return fmt.Errorf("Can't copy attributes: %v", err)
+		return fmt.Errorf("Can't backup %s: %v", path, err)
 	}
 
+	addBackupInfo(action.Command.Recipe, info)
+
 	return nil
 }
 
 // BackupRestore is action processor for "backup-restore"
-func BackupRestore(action *recipe.Action, tmpDir string) error {
+func BackupRestore(action *recipe.Action) error {
 	path, err := action.GetS(0)
 
 	if err != nil {
@@ -73,32 +112,80 @@ func BackupRestore(action *recipe.Action, tmpDir string) error {
 		return err
 	}
 
-	pathCRC32 := calcCRC32Q(path)
-	backupFile := tmpDir + "/" + pathCRC32
-
-	switch {
-	case !isSafePath:
+	if !isSafePath {
 		return fmt.Errorf("Path is unsafe (%s)", path)
-	case !fsutil.IsExist(backupFile):
-		return fmt.Errorf("Backup file for %s does not exist", path)
 	}
 
-	err = os.Remove(path)
+	path, err = filepath.Abs(path)
 
 	if err != nil {
-		return fmt.Errorf("Can't remove original file: %v", err)
+		return err
 	}
 
-	err = fsutil.CopyFile(backupFile, path)
+	info := getBackupInfo(action.Command.Recipe, path)
+
+	if info == nil {
+		return fmt.Errorf("Backup for %s does not exist", path)
+	}
+
+	err = info.restore()
 
 	if err != nil {
-		return fmt.Errorf("Can't copy backup file: %v", err)
+		return err
 	}
 
-	err = fsutil.CopyAttr(backupFile, path)
+	// Object restored explicitly must not be restored again after processing
+	// all commands, otherwise changes made by next commands will be lost
+	info.IsRestored = true
+
+	return nil
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// RestoreBackups restores all backups created by recipe in reverse order. Objects
+// restored by "backup-restore" action are skipped.
+func RestoreBackups(r *recipe.Recipe) []error {
+	var errs []error
+
+	backups := getBackups(r)
+
+	for i := len(backups) - 1; i >= 0; i-- {
+		if backups[i].IsRestored {
+			continue
+		}
+
+		err := backups[i].restore()
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// restore restores object from backup. All objects created after backup
+// creation will be removed.
+func (b *backupInfo) restore() error {
+	err := os.RemoveAll(b.Path)
+
+	if err != nil {
+		return fmt.Errorf("Can't remove %s: %v", b.Path, err)
+	}
+
+	err = copyObject(b.Backup, b.Path)
 
 	if err != nil {
-		return fmt.Errorf("Can't copy attributes: %v", err)
+		return fmt.Errorf("Can't restore %s from backup: %v", b.Path, err)
+	}
+
+	err = setTreeXattrs(b.Path, b.Xattrs)
+
+	if err != nil {
+		return fmt.Errorf("Can't restore %s from backup: %v", b.Path, err)
 	}
 
 	return nil
@@ -106,8 +193,37 @@ func BackupRestore(action *recipe.Action, tmpDir string) error {
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
-// calcCRC32Q calculate CRC32 checksum
-func calcCRC32Q(data string) string {
-	table := crc32.MakeTable(0xD5828281)
-	return fmt.Sprintf("%08x", crc32.Checksum([]byte(data), table))
+// addBackupInfo adds or replaces info about backup
+func addBackupInfo(r *recipe.Recipe, info *backupInfo) {
+	var backups []*backupInfo
+
+	for _, b := range getBackups(r) {
+		if b.Path != info.Path {
+			backups = append(backups, b)
+		}
+	}
+
+	r.Data.Set(PROP_BACKUPS, append(backups, info))
+}
+
+// getBackupInfo returns info about backup of object with given path
+func getBackupInfo(r *recipe.Recipe, path string) *backupInfo {
+	for _, b := range getBackups(r) {
+		if b.Path == path {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// getBackups returns info about all backups
+func getBackups(r *recipe.Recipe) []*backupInfo {
+	if !r.Data.Has(PROP_BACKUPS) {
+		return nil
+	}
+
+	backups, _ := r.Data.Get(PROP_BACKUPS).([]*backupInfo)
+
+	return backups
 }
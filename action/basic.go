@@ -10,6 +10,9 @@ package action
 import (
 	"fmt"
 	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -21,6 +24,36 @@ import (
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
+// sysexits contains exit codes from sysexits.h
+var sysexits = map[string]int{
+	"EX_OK":          0,
+	"EX_USAGE":       64,
+	"EX_DATAERR":     65,
+	"EX_NOINPUT":     66,
+	"EX_NOUSER":      67,
+	"EX_NOHOST":      68,
+	"EX_UNAVAILABLE": 69,
+	"EX_SOFTWARE":    70,
+	"EX_OSERR":       71,
+	"EX_OSFILE":      72,
+	"EX_CANTCREAT":   73,
+	"EX_IOERR":       74,
+	"EX_TEMPFAIL":    75,
+	"EX_PROTOCOL":    76,
+	"EX_NOPERM":      77,
+	"EX_CONFIG":      78,
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// exitCondition contains exit codes and signals expected by "exit" action
+type exitCondition struct {
+	Codes   [][2]int
+	Signals []syscall.Signal
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
 // Wait is action processor for "exit"
 func Wait(action *recipe.Action) error {
 	durSec, err := action.GetF(0)
@@ -31,7 +64,9 @@ func Wait(action *recipe.Action) error {
 
 	durSec = mathutil.Between(durSec, 0.01, 3600.0)
 
-	time.Sleep(timeutil.SecondsToDuration(durSec))
+	if !sleep(action, timeutil.SecondsToDuration(durSec)) {
+		return ErrAborted
+	}
 
 	return nil
 }
@@ -42,12 +77,16 @@ func Exit(action *recipe.Action, cmd *exec.Cmd) error {
 		return nil
 	}
 
-	var err error
 	var start time.Time
-	var exitCode int
 	var timeout float64
 
-	exitCode, err = action.GetI(0)
+	condStr, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	cond, err := parseExitCondition(condStr)
 
 	if err != nil {
 		return err
@@ -65,8 +104,10 @@ func Exit(action *recipe.Action, cmd *exec.Cmd) error {
 
 	start = time.Now()
 
-	for range time.NewTicker(25 * time.Millisecond).C {
-		if cmd.ProcessState != nil && cmd.ProcessState.Exited() {
+	// We can't use ProcessState.Exited here because it returns false if the
+	// process was killed by signal
+	for sleep(action, 25*time.Millisecond) {
+		if cmd.ProcessState != nil {
 			break
 		}
 
@@ -75,18 +116,154 @@ func Exit(action *recipe.Action, cmd *exec.Cmd) error {
 		}
 	}
 
+	if cmd.ProcessState == nil {
+		return ErrAborted
+	}
+
 	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
 
 	if !ok {
 		return fmt.Errorf("Can't get exit code from process state")
 	}
 
+	isMatch := cond.IsMatch(status)
+
+	// runuser reports the signal which killed the command as exit code 128+N
+	if !isMatch && action.Command != nil && action.Command.User != "" {
+		wrappedStatus, ok := getWrappedStatus(status)
+
+		if ok && (cond.IsMatch(wrappedStatus) || len(cond.Codes) == 0) {
+			status, isMatch = wrappedStatus, cond.IsMatch(wrappedStatus)
+		}
+	}
+
 	switch {
-	case !action.Negative && status.ExitStatus() != exitCode:
-		return fmt.Errorf("The process has exited with invalid exit code (%d ≠ %d)", status.ExitStatus(), exitCode)
-	case action.Negative && status.ExitStatus() == exitCode:
+	case !action.Negative && !isMatch && status.Signaled() && len(cond.Signals) == 0:
+		return fmt.Errorf(
+			"The process was killed by signal %s instead of exiting with code %s",
+			formatTermSignal(status), condStr,
+		)
+	case !action.Negative && !isMatch && !status.Signaled() && len(cond.Codes) == 0:
+		return fmt.Errorf(
+			"The process has exited with code %d instead of being killed by signal %s",
+			status.ExitStatus(), condStr,
+		)
+	case !action.Negative && !isMatch && status.Signaled():
+		return fmt.Errorf(
+			"The process was killed by invalid signal (%s ≠ %s)",
+			formatTermSignal(status), condStr,
+		)
+	case !action.Negative && !isMatch:
+		return fmt.Errorf("The process has exited with invalid exit code (%d ≠ %s)", status.ExitStatus(), condStr)
+	case action.Negative && isMatch && status.Signaled():
+		return fmt.Errorf("The process was killed by signal %s", formatTermSignal(status))
+	case action.Negative && isMatch:
 		return fmt.Errorf("The process has exited with invalid exit code (%d)", status.ExitStatus())
 	}
 
 	return nil
 }
+
+// Running is action processor for "running"
+func Running(action *recipe.Action, cmd *exec.Cmd) error {
+	durSec, err := action.GetF(0)
+
+	if err != nil {
+		return err
+	}
+
+	durSec = mathutil.Between(durSec, 0.01, 3600.0)
+	deadline := time.Now().Add(timeutil.SecondsToDuration(durSec))
+
+	for sleep(action, 25*time.Millisecond) {
+		if cmd.ProcessState != nil || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	if isAborted(action) {
+		return ErrAborted
+	}
+
+	if cmd.ProcessState == nil {
+		return nil
+	}
+
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+
+	switch {
+	case ok && status.Signaled():
+		return fmt.Errorf(
+			"The process was killed by signal %s in less than %gs",
+			formatTermSignal(status), durSec,
+		)
+	case ok:
+		return fmt.Errorf(
+			"The process has exited with code %d in less than %gs",
+			status.ExitStatus(), durSec,
+		)
+	}
+
+	return fmt.Errorf("The process has finished in less than %gs", durSec)
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// IsMatch returns true if process exit status matches condition
+func (c *exitCondition) IsMatch(status syscall.WaitStatus) bool {
+	if status.Signaled() {
+		return slices.Contains(c.Signals, status.Signal())
+	}
+
+	for _, r := range c.Codes {
+		if status.ExitStatus() >= r[0] && status.ExitStatus() <= r[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// parseExitCondition parses comma-separated list of exit codes, ranges of exit
+// codes, sysexits names and signal names
+func parseExitCondition(v string) (*exitCondition, error) {
+	cond := &exitCondition{}
+
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		code, isSysexit := sysexits[strings.ToUpper(item)]
+		from, to, isRange := strings.Cut(item, "-")
+
+		switch {
+		case isNumber(item):
+			code, _ = strconv.Atoi(item)
+			cond.Codes = append(cond.Codes, [2]int{code, code})
+
+		case isSysexit:
+			cond.Codes = append(cond.Codes, [2]int{code, code})
+
+		case isRange && isNumber(from) && isNumber(to):
+			codeFrom, _ := strconv.Atoi(from)
+			codeTo, _ := strconv.Atoi(to)
+
+			if codeFrom > codeTo {
+				return nil, fmt.Errorf("Invalid range of exit codes %q", item)
+			}
+
+			cond.Codes = append(cond.Codes, [2]int{codeFrom, codeTo})
+
+		default:
+			sig, err := parseSignal(item)
+
+			if err != nil {
+				return nil, fmt.Errorf("Invalid exit code or signal %q", item)
+			}
+
+			cond.Signals = append(cond.Signals, sig)
+		}
+	}
+
+	return cond, nil
+}
@@ -0,0 +1,131 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"syscall"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// signalNames is a slice with names of signals
+var signalNames = []string{
+	"SIGABRT", "SIGALRM", "SIGBUS", "SIGCHLD", "SIGCONT", "SIGFPE", "SIGHUP",
+	"SIGILL", "SIGINT", "SIGIO", "SIGKILL", "SIGPIPE", "SIGPROF", "SIGQUIT",
+	"SIGSEGV", "SIGSTOP", "SIGSYS", "SIGTERM", "SIGTRAP", "SIGTSTP", "SIGTTIN",
+	"SIGTTOU", "SIGURG", "SIGUSR1", "SIGUSR2", "SIGVTALRM", "SIGWINCH",
+	"SIGXCPU", "SIGXFSZ",
+}
+
+// crashSignals is a slice with signals which default action is core dump
+var crashSignals = []syscall.Signal{
+	syscall.SIGABRT, syscall.SIGBUS, syscall.SIGFPE, syscall.SIGILL,
+	syscall.SIGQUIT, syscall.SIGSEGV, syscall.SIGSYS, syscall.SIGTRAP,
+	syscall.SIGXCPU, syscall.SIGXFSZ,
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// GetCrashInfo returns info about crash of finished process or nil if process
+// wasn't crashed
+func GetCrashInfo(cmd *exec.Cmd) *recipe.CrashInfo {
+	if cmd == nil || cmd.ProcessState == nil {
+		return nil
+	}
+
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+
+	if !ok || !status.Signaled() || !isCrashSignal(status.Signal()) {
+		return nil
+	}
+
+	return &recipe.CrashInfo{
+		Signal:     getSignalName(status.Signal()),
+		CoreDumped: status.CoreDump(),
+	}
+}
+
+// GetWrappedCrashInfo returns info about crash of process executed by wrapper
+// (e.g. runuser) which exits with code 128+N if the process was killed by
+// signal N
+func GetWrappedCrashInfo(cmd *exec.Cmd) *recipe.CrashInfo {
+	crash := GetCrashInfo(cmd)
+
+	if crash != nil || cmd == nil || cmd.ProcessState == nil {
+		return crash
+	}
+
+	sig, ok := getWrappedSignal(cmd.ProcessState.ExitCode())
+
+	if !ok || !isCrashSignal(sig) {
+		return nil
+	}
+
+	return &recipe.CrashInfo{Signal: getSignalName(sig)}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// isCrashSignal returns true if given signal means that process was crashed
+func isCrashSignal(sig syscall.Signal) bool {
+	return slices.Contains(crashSignals, sig)
+}
+
+// getWrappedSignal returns signal N from exit code 128+N returned by wrapper
+func getWrappedSignal(code int) (syscall.Signal, bool) {
+	if code <= 128 || code > 128+64 {
+		return 0, false
+	}
+
+	return syscall.Signal(code - 128), true
+}
+
+// getWrappedStatus returns status of process killed by signal from status of
+// wrapper which has exited with code 128+N
+func getWrappedStatus(status syscall.WaitStatus) (syscall.WaitStatus, bool) {
+	if !status.Exited() {
+		return status, false
+	}
+
+	sig, ok := getWrappedSignal(status.ExitStatus())
+
+	if !ok {
+		return status, false
+	}
+
+	// The lowest 7 bits of wait status contain number of signal which killed
+	// the process
+	return syscall.WaitStatus(sig), true
+}
+
+// getSignalName returns name of signal
+func getSignalName(sig syscall.Signal) string {
+	for _, name := range signalNames {
+		s, err := parseSignal(name)
+
+		if err == nil && s == sig {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("signal %d", int(sig))
+}
+
+// formatTermSignal returns name of signal which killed the process
+func formatTermSignal(status syscall.WaitStatus) string {
+	if status.CoreDump() {
+		return getSignalName(status.Signal()) + " (core dumped)"
+	}
+
+	return getSignalName(status.Signal())
+}
@@ -0,0 +1,505 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/essentialkaos/ek/v13/fsutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const (
+	xattrCapability = "security.capability"
+	xattrSELinux    = "security.selinux"
+)
+
+const (
+	vfsCapRevisionMask = 0xFF000000
+	vfsCapRevision1    = 0x01000000
+	vfsCapRevision2    = 0x02000000
+	vfsCapFlagsEff     = 0x000001
+)
+
+const (
+	fsIOCGetFlags = 0x80086601
+	fsIOCSetFlags = 0x40086602
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// fileAttrs contains names of file attributes and their flags
+var fileAttrs = map[string]int32{
+	"sync":        0x00000008,
+	"immutable":   0x00000010,
+	"append-only": 0x00000020,
+	"no-dump":     0x00000040,
+	"no-atime":    0x00000080,
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// FileCap is action processor for "file-cap"
+func FileCap(action *recipe.Action) error {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	caps, err := getActionCaps(action)
+
+	if err != nil {
+		return err
+	}
+
+	if !fsutil.IsExist(file) {
+		return fmt.Errorf("File %s doesn't exist", file)
+	}
+
+	fileCaps, err := getFileCaps(file)
+
+	if err != nil {
+		return err
+	}
+
+	for _, capNum := range caps {
+		hasCap := fileCaps&(1<<capNum) != 0
+
+		switch {
+		case !action.Negative && !hasCap:
+			return fmt.Errorf("File %s doesn't have capability %s", file, capNames[capNum])
+		case action.Negative && hasCap:
+			return fmt.Errorf("File %s has capability %s", file, capNames[capNum])
+		}
+	}
+
+	return nil
+}
+
+// FileCapSet is action processor for "file-cap-set"
+func FileCapSet(action *recipe.Action) error {
+	file, err := getSafeActionPath(action)
+
+	if err != nil {
+		return err
+	}
+
+	capName, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	if capName == "none" {
+		err = syscall.Removexattr(file, xattrCapability)
+
+		if err != nil && !errors.Is(err, syscall.ENODATA) {
+			return fmt.Errorf("Can't remove capabilities of %s: %v", file, err)
+		}
+
+		return nil
+	}
+
+	caps, err := getActionCaps(action)
+
+	if err != nil {
+		return err
+	}
+
+	var fileCaps uint64
+
+	for _, capNum := range caps {
+		fileCaps |= 1 << capNum
+	}
+
+	// struct vfs_cap_data with revision 2: magic_etc and two pairs of
+	// permitted and inheritable sets
+	data := make([]byte, 20)
+
+	binary.LittleEndian.PutUint32(data[0:], vfsCapRevision2|vfsCapFlagsEff)
+	binary.LittleEndian.PutUint32(data[4:], uint32(fileCaps))
+	binary.LittleEndian.PutUint32(data[12:], uint32(fileCaps>>32))
+
+	err = syscall.Setxattr(file, xattrCapability, data, 0)
+
+	if err != nil {
+		return fmt.Errorf("Can't set capabilities of %s: %v", file, err)
+	}
+
+	return nil
+}
+
+// Xattr is action processor for "xattr"
+func Xattr(action *recipe.Action) error {
+	var value string
+
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	name, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	if action.Has(2) {
+		value, err = action.GetS(2)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if !fsutil.IsExist(file) {
+		return fmt.Errorf("File %s doesn't exist", file)
+	}
+
+	fileValue, isSet, err := readXattr(file, name)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !action.Negative && !isSet:
+		return fmt.Errorf("File %s doesn't have attribute %s", file, name)
+	case !action.Negative && action.Has(2) && fileValue != value:
+		return fmt.Errorf(
+			"File %s has different value of attribute %s (%s ≠ %s)",
+			file, name, fmtValue(fileValue), fmtValue(value),
+		)
+	case action.Negative && isSet && !action.Has(2):
+		return fmt.Errorf("File %s has attribute %s", file, name)
+	case action.Negative && isSet && fileValue == value:
+		return fmt.Errorf(
+			"File %s has invalid value of attribute %s (%s)",
+			file, name, fmtValue(value),
+		)
+	}
+
+	return nil
+}
+
+// XattrSet is action processor for "xattr-set"
+func XattrSet(action *recipe.Action) error {
+	file, err := getSafeActionPath(action)
+
+	if err != nil {
+		return err
+	}
+
+	name, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	value, err := action.GetS(2)
+
+	if err != nil {
+		return err
+	}
+
+	err = syscall.Setxattr(file, name, []byte(value), 0)
+
+	if err != nil {
+		return fmt.Errorf("Can't set attribute %s of %s: %v", name, file, err)
+	}
+
+	return nil
+}
+
+// SELinuxContext is action processor for "selinux-context"
+func SELinuxContext(action *recipe.Action) error {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	context, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	if !fsutil.IsExist(file) {
+		return fmt.Errorf("File %s doesn't exist", file)
+	}
+
+	fileContext, isSet, err := readXattr(file, xattrSELinux)
+
+	if err != nil {
+		return err
+	}
+
+	if !isSet {
+		return fmt.Errorf("File %s doesn't have SELinux context (SELinux disabled?)", file)
+	}
+
+	isMatch := fileContext == context
+
+	// If context contains only type, we compare only type part of the context
+	// (user:role:type:level)
+	if !strings.Contains(context, ":") {
+		parts := strings.Split(fileContext, ":")
+		isMatch = len(parts) > 2 && parts[2] == context
+	}
+
+	switch {
+	case !action.Negative && !isMatch:
+		return fmt.Errorf(
+			"File %s has different SELinux context (%s ≠ %s)",
+			file, fileContext, context,
+		)
+	case action.Negative && isMatch:
+		return fmt.Errorf("File %s has invalid SELinux context (%s)", file, fileContext)
+	}
+
+	return nil
+}
+
+// SELinuxContextSet is action processor for "selinux-context-set"
+func SELinuxContextSet(action *recipe.Action) error {
+	file, err := getSafeActionPath(action)
+
+	if err != nil {
+		return err
+	}
+
+	context, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	err = syscall.Setxattr(file, xattrSELinux, append([]byte(context), 0), 0)
+
+	if err != nil {
+		return fmt.Errorf("Can't set SELinux context of %s: %v", file, err)
+	}
+
+	return nil
+}
+
+// FileAttr is action processor for "file-attr"
+func FileAttr(action *recipe.Action) error {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	if !fsutil.IsExist(file) {
+		return fmt.Errorf("File %s doesn't exist", file)
+	}
+
+	flags, err := getFileFlags(file)
+
+	if err != nil {
+		return err
+	}
+
+	for index := 1; index < len(action.Arguments); index++ {
+		attr, _ := action.GetS(index)
+		flag, ok := fileAttrs[attr]
+
+		if !ok {
+			return fmt.Errorf("Unknown file attribute %q", attr)
+		}
+
+		hasAttr := flags&flag != 0
+
+		switch {
+		case !action.Negative && !hasAttr:
+			return fmt.Errorf("File %s doesn't have attribute %s", file, attr)
+		case action.Negative && hasAttr:
+			return fmt.Errorf("File %s has attribute %s", file, attr)
+		}
+	}
+
+	return nil
+}
+
+// FileAttrSet is action processor for "file-attr-set"
+func FileAttrSet(action *recipe.Action) error {
+	file, err := getSafeActionPath(action)
+
+	if err != nil {
+		return err
+	}
+
+	flags, err := getFileFlags(file)
+
+	if err != nil {
+		return err
+	}
+
+	for index := 1; index < len(action.Arguments); index++ {
+		attr, _ := action.GetS(index)
+		isUnset := strings.HasPrefix(attr, "-")
+		flag, ok := fileAttrs[strings.TrimLeft(attr, "+-")]
+
+		if !ok {
+			return fmt.Errorf("Unknown file attribute %q", attr)
+		}
+
+		if isUnset {
+			flags &^= flag
+		} else {
+			flags |= flag
+		}
+	}
+
+	return setFileFlags(file, flags)
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getSafeActionPath returns path from the first action argument if it is safe
+func getSafeActionPath(action *recipe.Action) (string, error) {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return "", err
+	}
+
+	isSafePath, err := checkPathSafety(action.Command.Recipe, file)
+
+	if err != nil {
+		return "", err
+	}
+
+	if !isSafePath {
+		return "", fmt.Errorf("Path %q is unsafe", file)
+	}
+
+	if !fsutil.IsExist(file) {
+		return "", fmt.Errorf("File %s doesn't exist", file)
+	}
+
+	return file, nil
+}
+
+// getActionCaps parses capability names from action arguments starting from
+// the second one
+func getActionCaps(action *recipe.Action) ([]int, error) {
+	var result []int
+
+	for index := 1; index < len(action.Arguments); index++ {
+		capName, _ := action.GetS(index)
+		capNum, err := parseCapName(capName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, capNum)
+	}
+
+	return result, nil
+}
+
+// getFileCaps returns permitted capabilities of file
+func getFileCaps(file string) (uint64, error) {
+	data, err := getXattr(file, xattrCapability)
+
+	if errors.Is(err, syscall.ENODATA) || errors.Is(err, syscall.ENOTSUP) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Can't read capabilities of %s: %v", file, err)
+	}
+
+	if len(data) < 12 {
+		return 0, fmt.Errorf("Can't parse capabilities of %s: data is too short", file)
+	}
+
+	caps := uint64(binary.LittleEndian.Uint32(data[4:]))
+
+	// Revision 1 supports only 32 capabilities
+	if binary.LittleEndian.Uint32(data)&vfsCapRevisionMask != vfsCapRevision1 && len(data) >= 20 {
+		caps |= uint64(binary.LittleEndian.Uint32(data[12:])) << 32
+	}
+
+	return caps, nil
+}
+
+// readXattr returns value of extended attribute as a string
+func readXattr(file, name string) (string, bool, error) {
+	data, err := getXattr(file, name)
+
+	if errors.Is(err, syscall.ENODATA) || errors.Is(err, syscall.ENOTSUP) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("Can't read attribute %s of %s: %v", name, file, err)
+	}
+
+	return string(bytes.TrimRight(data, "\x00")), true, nil
+}
+
+// getFileFlags returns inode flags of file
+func getFileFlags(file string) (int32, error) {
+	var flags int32
+
+	fd, err := os.OpenFile(file, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+
+	if err != nil {
+		return 0, fmt.Errorf("Can't open file %s: %v", file, err)
+	}
+
+	defer fd.Close()
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL, fd.Fd(), fsIOCGetFlags, uintptr(unsafe.Pointer(&flags)),
+	)
+
+	if errno != 0 {
+		return 0, fmt.Errorf("Can't read attributes of %s: %v", file, errno)
+	}
+
+	return flags, nil
+}
+
+// setFileFlags sets inode flags of file
+func setFileFlags(file string, flags int32) error {
+	fd, err := os.OpenFile(file, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+
+	if err != nil {
+		return fmt.Errorf("Can't open file %s: %v", file, err)
+	}
+
+	defer fd.Close()
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL, fd.Fd(), fsIOCSetFlags, uintptr(unsafe.Pointer(&flags)),
+	)
+
+	if errno != 0 {
+		return fmt.Errorf("Can't set attributes of %s: %v", file, errno)
+	}
+
+	return nil
+}
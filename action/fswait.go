@@ -0,0 +1,349 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/fmtutil"
+	"github.com/essentialkaos/ek/v13/mathutil"
+	"github.com/essentialkaos/ek/v13/timeutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const PROP_FS_STATE = "FS_STATE"
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// inotifyEvents is a mask with inotify events related to changes of files
+const inotifyEvents = syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_CREATE |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_CLOSE_WRITE
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// WaitFSModify is action processor for "wait-fs-modify"
+func WaitFSModify(action *recipe.Action) error {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	timeout, err := getWaitTimeout(action, 1)
+
+	if err != nil {
+		return err
+	}
+
+	initState, ok := action.Command.Data.Get(PROP_FS_STATE + ":" + file).(string)
+
+	if !ok {
+		initState = getFSState(file)
+	}
+
+	ok, err = waitFSCondition(action, file, timeout, func() (bool, error) {
+		state := getFSState(file)
+		return state != "" && state != initState, nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("Timeout (%g sec) reached, and %s wasn't modified", timeout, file)
+	}
+
+	return nil
+}
+
+// WaitFSSize is action processor for "wait-fs-size"
+func WaitFSSize(action *recipe.Action) error {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	sizeStr, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	size := fmtutil.ParseSize(sizeStr)
+
+	if size == 0 && sizeStr != "0" {
+		return fmt.Errorf("Can't parse size %q", sizeStr)
+	}
+
+	timeout, err := getWaitTimeout(action, 2)
+
+	if err != nil {
+		return err
+	}
+
+	ok, err := waitFSCondition(action, file, timeout, func() (bool, error) {
+		info, err := os.Stat(file)
+		return err == nil && uint64(info.Size()) >= size, nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf(
+			"Timeout (%g sec) reached, and size of %s is still less than %s",
+			timeout, file, fmtutil.PrettySize(size),
+		)
+	}
+
+	return nil
+}
+
+// WaitFSSettle is action processor for "wait-fs-settle"
+func WaitFSSettle(action *recipe.Action) error {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	period, err := action.GetI(1)
+
+	if err != nil {
+		return err
+	}
+
+	if period <= 0 {
+		return fmt.Errorf("Settle period must be greater than 0")
+	}
+
+	timeout, err := getWaitTimeout(action, 2)
+
+	if err != nil {
+		return err
+	}
+
+	var lastState string
+
+	settleDur := time.Duration(period) * time.Millisecond
+	lastChange := time.Now()
+	deadline := time.Now().Add(timeutil.SecondsToDuration(timeout))
+	watcher := newInotifyWatcher(filepath.Dir(file))
+
+	if watcher != nil {
+		defer watcher.Close()
+	}
+
+	for {
+		state := getFSState(file)
+
+		if state != lastState {
+			lastState, lastChange = state, time.Now()
+		}
+
+		if state != "" && time.Since(lastChange) >= settleDur {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			break
+		}
+
+		if isAborted(action) {
+			return ErrAborted
+		}
+
+		// There is nothing to settle while object doesn't exist
+		if state == "" {
+			waitFSEvents(watcher, time.Until(deadline))
+		} else {
+			waitFSEvents(watcher, min(time.Until(deadline), time.Until(lastChange.Add(settleDur))))
+		}
+	}
+
+	return fmt.Errorf(
+		"Timeout (%g sec) reached, and %s didn't settle for %d ms",
+		timeout, file, period,
+	)
+}
+
+// WaitFSContains is action processor for "wait-fs-contains"
+func WaitFSContains(action *recipe.Action) error {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	substr, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	timeout, err := getWaitTimeout(action, 2)
+
+	if err != nil {
+		return err
+	}
+
+	ok, err := waitFSCondition(action, file, timeout, func() (bool, error) {
+		data, err := os.ReadFile(file)
+		return err == nil && bytes.Contains(data, []byte(substr)), nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf(
+			"Timeout (%g sec) reached, and %s doesn't contain %q",
+			timeout, file, substr,
+		)
+	}
+
+	return nil
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// SaveFSState saves state of all files used in "wait-fs-modify" actions before
+// the command start
+func SaveFSState(c *recipe.Command) {
+	for _, a := range c.Actions {
+		if a.Name != recipe.ACTION_WAIT_FS_MODIFY {
+			continue
+		}
+
+		file, err := a.GetS(0)
+
+		if err != nil {
+			continue
+		}
+
+		c.Data.Set(PROP_FS_STATE+":"+file, getFSState(file))
+	}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getWaitTimeout returns timeout in seconds from action argument with given index
+func getWaitTimeout(action *recipe.Action, index int) (float64, error) {
+	if !action.Has(index) {
+		return 60.0, nil
+	}
+
+	timeout, err := action.GetF(index)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return mathutil.Between(timeout, 0.01, 3600.0), nil
+}
+
+// waitFSCondition waits until condition related to given path is met or timeout
+// is reached
+func waitFSCondition(action *recipe.Action, path string, timeout float64, isDone func() (bool, error)) (bool, error) {
+	deadline := time.Now().Add(timeutil.SecondsToDuration(timeout))
+	watcher := newInotifyWatcher(filepath.Dir(path))
+
+	if watcher != nil {
+		defer watcher.Close()
+	}
+
+	for {
+		ok, err := isDone()
+
+		if err != nil || ok {
+			return ok, err
+		}
+
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+
+		if isAborted(action) {
+			return false, ErrAborted
+		}
+
+		waitFSEvents(watcher, time.Until(deadline))
+	}
+}
+
+// getFSState returns string with info about inode, size and modification time
+// of object or empty string if object doesn't exist
+func getFSState(path string) string {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return ""
+	}
+
+	var ino uint64
+
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		ino = stat.Ino
+	}
+
+	return strconv.FormatUint(ino, 10) + ":" +
+		strconv.FormatInt(info.Size(), 10) + ":" +
+		strconv.FormatInt(info.ModTime().UnixNano(), 10)
+}
+
+// newInotifyWatcher creates inotify watcher for directory
+func newInotifyWatcher(dir string) *os.File {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+
+	if err != nil {
+		return nil
+	}
+
+	_, err = syscall.InotifyAddWatch(fd, dir, inotifyEvents)
+
+	if err != nil {
+		syscall.Close(fd)
+		return nil
+	}
+
+	return os.NewFile(uintptr(fd), "inotify")
+}
+
+// waitFSEvents waits for inotify events or for a short time if inotify is not
+// available
+func waitFSEvents(watcher *os.File, maxWait time.Duration) {
+	if maxWait <= 0 {
+		return
+	}
+
+	if watcher == nil {
+		time.Sleep(min(25*time.Millisecond, maxWait))
+		return
+	}
+
+	buf := make([]byte, 4096)
+
+	// We also periodically check objects to handle events in other directories
+	// (e.g. if directory was created after the command start)
+	watcher.SetReadDeadline(time.Now().Add(min(250*time.Millisecond, maxWait)))
+	watcher.Read(buf)
+}
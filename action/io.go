@@ -51,7 +51,7 @@ func Expect(action *recipe.Action, output *OutputContainer) error {
 	timeout = mathutil.Between(timeout, 0.01, 3600.0)
 	timeoutDur := timeutil.SecondsToDuration(timeout)
 
-	for range time.NewTicker(_DATA_READ_PERIOD).C {
+	for sleep(action, _DATA_READ_PERIOD) {
 		if bytes.Contains(output.Bytes(), []byte(substr)) {
 			output.Purge()
 			return nil
@@ -62,6 +62,10 @@ func Expect(action *recipe.Action, output *OutputContainer) error {
 		}
 	}
 
+	if isAborted(action) {
+		return ErrAborted
+	}
+
 	return fmt.Errorf("Timeout (%g sec) reached", timeout)
 }
 
@@ -76,7 +80,7 @@ func WaitOutput(action *recipe.Action, output *OutputContainer) error {
 	start := time.Now()
 	timeoutDur := timeutil.SecondsToDuration(timeout)
 
-	for range time.NewTicker(_DATA_READ_PERIOD).C {
+	for sleep(action, _DATA_READ_PERIOD) {
 		if !output.IsEmpty() {
 			return nil
 		}
@@ -86,6 +90,10 @@ func WaitOutput(action *recipe.Action, output *OutputContainer) error {
 		}
 	}
 
+	if isAborted(action) {
+		return ErrAborted
+	}
+
 	return fmt.Errorf("Timeout (%g sec) reached, but output still empty", timeout)
 }
 
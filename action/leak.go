@@ -0,0 +1,396 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/fmtutil"
+	"github.com/essentialkaos/ek/v13/fsutil"
+	"github.com/essentialkaos/ek/v13/pid"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const PROP_PROC_SNAPSHOT = "PROC_SNAPSHOT"
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const (
+	PROC_METRIC_FDS     = "fds"
+	PROC_METRIC_THREADS = "threads"
+	PROC_METRIC_RSS     = "rss"
+	PROC_METRIC_MAPS    = "maps"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// procSnapshot contains info about resources used by process
+type procSnapshot struct {
+	PID     int
+	FDs     uint64
+	Threads uint64
+	RSS     uint64
+	Maps    uint64
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// ProcSnapshot is action processor for "proc-snapshot"
+func ProcSnapshot(action *recipe.Action, cmd *exec.Cmd) error {
+	name, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := makeProcSnapshot(pid)
+
+	if err != nil {
+		return err
+	}
+
+	action.Command.Recipe.Data.Set(PROP_PROC_SNAPSHOT+":"+name, snapshot)
+
+	return nil
+}
+
+// ProcGrowth is action processor for "proc-growth"
+func ProcGrowth(action *recipe.Action, cmd *exec.Cmd) error {
+	name, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	metric, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	toleranceStr, err := action.GetS(2)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 3, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	prev, ok := action.Command.Recipe.Data.Get(PROP_PROC_SNAPSHOT + ":" + name).(*procSnapshot)
+
+	if !ok {
+		return fmt.Errorf("Process snapshot %q doesn't exist", name)
+	}
+
+	if prev.PID != pid {
+		return fmt.Errorf(
+			"Process snapshot %q was made for another process (%d ≠ %d)",
+			name, prev.PID, pid,
+		)
+	}
+
+	cur, err := makeProcSnapshot(pid)
+
+	if err != nil {
+		return err
+	}
+
+	prevValue, curValue, err := getProcMetricValues(metric, prev, cur)
+
+	if err != nil {
+		return err
+	}
+
+	tolerance, err := parseProcTolerance(metric, toleranceStr, prevValue)
+
+	if err != nil {
+		return err
+	}
+
+	var growth uint64
+
+	if curValue > prevValue {
+		growth = curValue - prevValue
+	}
+
+	switch {
+	case !action.Negative && growth > tolerance:
+		return fmt.Errorf(
+			"Process %s grown by %s (%s → %s), tolerance is %s",
+			metric, formatProcMetric(metric, growth),
+			formatProcMetric(metric, prevValue), formatProcMetric(metric, curValue),
+			formatProcMetric(metric, tolerance),
+		)
+	case action.Negative && growth <= tolerance:
+		return fmt.Errorf(
+			"Process %s grown by %s (%s → %s), which is within tolerance %s",
+			metric, formatProcMetric(metric, growth),
+			formatProcMetric(metric, prevValue), formatProcMetric(metric, curValue),
+			formatProcMetric(metric, tolerance),
+		)
+	}
+
+	return nil
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getTargetPID returns PID from PID file in action argument with given index or
+// PID of command process
+func getTargetPID(action *recipe.Action, index int, cmd *exec.Cmd) (int, error) {
+	if action.Has(index) {
+		pidFile, err := action.GetS(index)
+
+		if err != nil {
+			return -1, err
+		}
+
+		ppid := pid.Read(pidFile)
+
+		if ppid == -1 {
+			return -1, ErrCantReadPIDFile
+		}
+
+		return ppid, nil
+	}
+
+	if cmd == nil {
+		return -1, fmt.Errorf("PID file is required for hollow commands (without executing binary)")
+	}
+
+	if cmd.Process == nil || cmd.ProcessState != nil {
+		return -1, fmt.Errorf("Can't find process PID (process already dead?)")
+	}
+
+	return getCommandPID(action, cmd.Process.Pid)
+}
+
+// getCommandPID returns PID of command process skipping wrappers used for running
+// it (procfs mount script, stdbuf and runuser with shell)
+func getCommandPID(action *recipe.Action, pid int) (int, error) {
+	var isUserShell bool
+
+	for range 200 {
+		args := getProcArgs(pid)
+
+		switch {
+		case len(args) == 0:
+			// Command line is empty while process is executing new binary
+			if !fsutil.IsExist("/proc/" + strconv.Itoa(pid)) {
+				return -1, fmt.Errorf("Can't find process PID (process already dead?)")
+			}
+
+		case isForkingWrapper(args, isUserShell):
+			children := getProcChildren(pid)
+
+			if len(children) > 1 {
+				return -1, fmt.Errorf("Can't find process PID (shell has several child processes, use PID file instead)")
+			}
+
+			if len(children) == 1 {
+				isUserShell = filepath.Base(args[0]) == "runuser"
+				pid = children[0]
+				continue
+			}
+
+		case !isExecWrapper(args):
+			return pid, nil
+		}
+
+		// Wait till wrapper executes or starts command
+		if !sleep(action, 10*time.Millisecond) {
+			return -1, ErrAborted
+		}
+	}
+
+	return -1, fmt.Errorf("Can't find process PID (wrapper process %d didn't start command, use PID file instead)", pid)
+}
+
+// getProcArgs returns command-line arguments of process with given PID
+func getProcArgs(pid int) []string {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline")
+
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+}
+
+// getProcChildren returns slice with PIDs of child processes
+func getProcChildren(pid int) []int {
+	var result []int
+
+	data, _ := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/children", pid, pid))
+
+	for _, child := range strings.Fields(string(data)) {
+		childPID, err := strconv.Atoi(child)
+
+		if err == nil {
+			result = append(result, childPID)
+		}
+	}
+
+	return result
+}
+
+// isExecWrapper returns true if process is wrapper which replaces itself with
+// the command using exec
+func isExecWrapper(args []string) bool {
+	switch filepath.Base(args[0]) {
+	case "stdbuf":
+		return true
+	case "sh":
+		// Script for mounting procfs inside PID namespace
+		return len(args) > 3 && args[1] == "-c" && args[3] == "sh" &&
+			strings.HasPrefix(args[2], "mount -t proc proc /proc")
+	}
+
+	return false
+}
+
+// isForkingWrapper returns true if process is wrapper which starts the command
+// as a child process
+func isForkingWrapper(args []string, isUserShell bool) bool {
+	switch filepath.Base(args[0]) {
+	case "runuser":
+		return true
+	case "bash":
+		return isUserShell && len(args) > 1 && args[1] == "-c"
+	}
+
+	return false
+}
+
+// makeProcSnapshot collects info about resources used by process
+func makeProcSnapshot(pid int) (*procSnapshot, error) {
+	procDir := "/proc/" + strconv.Itoa(pid)
+	status, err := os.ReadFile(procDir + "/status")
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read info about process %d: %v", pid, err)
+	}
+
+	fds, err := os.ReadDir(procDir + "/fd")
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read list of process %d descriptors: %v", pid, err)
+	}
+
+	maps, err := os.ReadFile(procDir + "/maps")
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read process %d memory maps: %v", pid, err)
+	}
+
+	snapshot := &procSnapshot{
+		PID:  pid,
+		FDs:  uint64(len(fds)),
+		Maps: uint64(bytes.Count(maps, []byte("\n"))),
+	}
+
+	for _, line := range strings.Split(string(status), "\n") {
+		name, value, ok := strings.Cut(line, ":")
+
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "Threads":
+			snapshot.Threads, _ = strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		case "VmRSS":
+			// Value is always in kilobytes
+			value = strings.TrimSuffix(strings.TrimSpace(value), " kB")
+			snapshot.RSS, _ = strconv.ParseUint(value, 10, 64)
+			snapshot.RSS *= 1024
+		}
+	}
+
+	return snapshot, nil
+}
+
+// getProcMetricValues returns values of metric from both snapshots
+func getProcMetricValues(metric string, prev, cur *procSnapshot) (uint64, uint64, error) {
+	switch metric {
+	case PROC_METRIC_FDS:
+		return prev.FDs, cur.FDs, nil
+	case PROC_METRIC_THREADS:
+		return prev.Threads, cur.Threads, nil
+	case PROC_METRIC_RSS:
+		return prev.RSS, cur.RSS, nil
+	case PROC_METRIC_MAPS:
+		return prev.Maps, cur.Maps, nil
+	}
+
+	return 0, 0, fmt.Errorf(
+		"Unknown metric %q (must be %q, %q, %q or %q)", metric,
+		PROC_METRIC_FDS, PROC_METRIC_THREADS, PROC_METRIC_RSS, PROC_METRIC_MAPS,
+	)
+}
+
+// parseProcTolerance parses tolerance as percentage of previous value, size
+// or number
+func parseProcTolerance(metric, v string, prevValue uint64) (uint64, error) {
+	if strings.HasSuffix(v, "%") {
+		perc, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+
+		if err != nil || perc < 0 {
+			return 0, fmt.Errorf("Can't parse tolerance %q", v)
+		}
+
+		return uint64(float64(prevValue) * perc / 100.0), nil
+	}
+
+	if metric == PROC_METRIC_RSS {
+		size := fmtutil.ParseSize(v)
+
+		if size == 0 && v != "0" {
+			return 0, fmt.Errorf("Can't parse size %q", v)
+		}
+
+		return size, nil
+	}
+
+	value, err := strconv.ParseUint(v, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("Can't parse tolerance %q", v)
+	}
+
+	return value, nil
+}
+
+// formatProcMetric formats metric value
+func formatProcMetric(metric string, value uint64) string {
+	if metric == PROC_METRIC_RSS {
+		return fmtutil.PrettySize(value)
+	}
+
+	return strconv.FormatUint(value, 10)
+}
@@ -0,0 +1,368 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"syscall"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const PROP_LOG_FOLLOWER = "LOG_FOLLOWER"
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// maxLogDataSize is max size of log data stored by follower
+const maxLogDataSize = 2 * 1024 * 1024
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// logFollower reads data appended to log file since command start
+type logFollower struct {
+	path  string
+	fd    *os.File
+	inode uint64
+	data  *bytes.Buffer
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// StartLogFollowing saves positions of all log files used in command actions
+func StartLogFollowing(c *recipe.Command) {
+	for _, a := range c.Actions {
+		switch a.Name {
+		case recipe.ACTION_LOG_CONTAINS, recipe.ACTION_LOG_MATCH,
+			recipe.ACTION_WAIT_LOG, recipe.ACTION_WAIT_LOG_MATCH:
+			// continue
+		default:
+			continue
+		}
+
+		file, err := a.GetS(0)
+
+		if err != nil {
+			continue
+		}
+
+		_, isFollowed := c.Data.Get(PROP_LOG_FOLLOWER + ":" + file).(*logFollower)
+
+		if isFollowed {
+			continue
+		}
+
+		c.Data.Set(PROP_LOG_FOLLOWER+":"+file, newLogFollower(file))
+	}
+}
+
+// StopLogFollowing closes all log files used in command actions
+func StopLogFollowing(c *recipe.Command) {
+	for _, a := range c.Actions {
+		file, err := a.GetS(0)
+
+		if err != nil {
+			continue
+		}
+
+		follower, ok := c.Data.Get(PROP_LOG_FOLLOWER + ":" + file).(*logFollower)
+
+		if ok {
+			follower.Close()
+			c.Data.Set(PROP_LOG_FOLLOWER+":"+file, nil)
+		}
+	}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// LogContains is action processor for "log-contains"
+func LogContains(action *recipe.Action) error {
+	file, follower, err := getLogFollower(action)
+
+	if err != nil {
+		return err
+	}
+
+	substr, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	err = follower.Read()
+
+	if err != nil {
+		return err
+	}
+
+	hasSubstr := bytes.Contains(follower.Data(), []byte(substr))
+
+	switch {
+	case !action.Negative && !hasSubstr:
+		return fmt.Errorf("New records in log %s don't contain %q", file, substr)
+	case action.Negative && hasSubstr:
+		return fmt.Errorf("New records in log %s contain %q", file, substr)
+	}
+
+	return nil
+}
+
+// LogMatch is action processor for "log-match"
+func LogMatch(action *recipe.Action) error {
+	file, follower, err := getLogFollower(action)
+
+	if err != nil {
+		return err
+	}
+
+	pattern, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	rg, err := regexp.Compile(pattern)
+
+	if err != nil {
+		return err
+	}
+
+	err = follower.Read()
+
+	if err != nil {
+		return err
+	}
+
+	isMatch := rg.Match(follower.Data())
+
+	switch {
+	case !action.Negative && !isMatch:
+		return fmt.Errorf("New records in log %s don't match regexp %q", file, pattern)
+	case action.Negative && isMatch:
+		return fmt.Errorf("New records in log %s match regexp %q", file, pattern)
+	}
+
+	return nil
+}
+
+// WaitLog is action processor for "wait-log"
+func WaitLog(action *recipe.Action) error {
+	substr, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	return waitLog(action, func(data []byte) bool {
+		return bytes.Contains(data, []byte(substr))
+	}, fmt.Sprintf("%q", substr))
+}
+
+// WaitLogMatch is action processor for "wait-log-match"
+func WaitLogMatch(action *recipe.Action) error {
+	pattern, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	rg, err := regexp.Compile(pattern)
+
+	if err != nil {
+		return err
+	}
+
+	return waitLog(action, rg.Match, fmt.Sprintf("record matching regexp %q", pattern))
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Read reads data appended to log file since the last read
+func (f *logFollower) Read() error {
+	info, err := os.Stat(f.path)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			// File was removed, but we still can read the rest of data
+			return f.readRest()
+		}
+
+		return fmt.Errorf("Can't read log %s: %v", f.path, err)
+	}
+
+	inode := getInode(info)
+
+	// File was rotated or recreated
+	if f.fd != nil && inode != f.inode {
+		err = f.readRest()
+
+		if err != nil {
+			return err
+		}
+
+		f.fd.Close()
+		f.fd = nil
+	}
+
+	if f.fd == nil {
+		f.fd, err = os.Open(f.path)
+
+		if err != nil {
+			return fmt.Errorf("Can't open log %s: %v", f.path, err)
+		}
+
+		f.inode = inode
+	}
+
+	offset, err := f.fd.Seek(0, io.SeekCurrent)
+
+	if err != nil {
+		return fmt.Errorf("Can't read log %s: %v", f.path, err)
+	}
+
+	// File was truncated
+	if info.Size() < offset {
+		_, err = f.fd.Seek(0, io.SeekStart)
+
+		if err != nil {
+			return fmt.Errorf("Can't read log %s: %v", f.path, err)
+		}
+	}
+
+	return f.readRest()
+}
+
+// Data returns all data appended to log file
+func (f *logFollower) Data() []byte {
+	return f.data.Bytes()
+}
+
+// Close closes log file
+func (f *logFollower) Close() {
+	if f.fd != nil {
+		f.fd.Close()
+		f.fd = nil
+	}
+}
+
+// readRest reads all data from current position to the end of file
+func (f *logFollower) readRest() error {
+	if f.fd == nil {
+		return nil
+	}
+
+	_, err := io.Copy(f.data, f.fd)
+
+	if err != nil {
+		return fmt.Errorf("Can't read log %s: %v", f.path, err)
+	}
+
+	// Keep only the newest data if there is too much data
+	if f.data.Len() > maxLogDataSize {
+		f.data = bytes.NewBuffer(bytes.Clone(f.data.Bytes()[f.data.Len()-maxLogDataSize:]))
+	}
+
+	return nil
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// newLogFollower creates log follower with position at the end of file
+func newLogFollower(file string) *logFollower {
+	follower := &logFollower{path: file, data: &bytes.Buffer{}}
+	fd, err := os.Open(file)
+
+	if err != nil {
+		return follower
+	}
+
+	info, err := fd.Stat()
+
+	if err != nil {
+		fd.Close()
+		return follower
+	}
+
+	_, err = fd.Seek(0, io.SeekEnd)
+
+	if err != nil {
+		fd.Close()
+		return follower
+	}
+
+	follower.fd, follower.inode = fd, getInode(info)
+
+	return follower
+}
+
+// getLogFollower returns log follower for file from the first action argument
+func getLogFollower(action *recipe.Action) (string, *logFollower, error) {
+	file, err := action.GetS(0)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	follower, ok := action.Command.Data.Get(PROP_LOG_FOLLOWER + ":" + file).(*logFollower)
+
+	if !ok {
+		return "", nil, fmt.Errorf("Log %s is not followed", file)
+	}
+
+	return file, follower, nil
+}
+
+// waitLog waits until log data satisfies given condition
+func waitLog(action *recipe.Action, isDone func(data []byte) bool, desc string) error {
+	file, follower, err := getLogFollower(action)
+
+	if err != nil {
+		return err
+	}
+
+	timeout, err := getWaitTimeout(action, 2)
+
+	if err != nil {
+		return err
+	}
+
+	ok, err := waitFSCondition(action, file, timeout, func() (bool, error) {
+		err := follower.Read()
+		return err == nil && isDone(follower.Data()), err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf(
+			"Timeout (%g sec) reached, and %s didn't appear in log %s",
+			timeout, desc, file,
+		)
+	}
+
+	return nil
+}
+
+// getInode returns inode of file
+func getInode(info os.FileInfo) uint64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return 0
+	}
+
+	return stat.Ino
+}
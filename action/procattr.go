@@ -0,0 +1,558 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/essentialkaos/ek/v13/system"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// capNames contains names of Linux capabilities (index is capability number)
+var capNames = []string{
+	"cap_chown", "cap_dac_override", "cap_dac_read_search", "cap_fowner",
+	"cap_fsetid", "cap_kill", "cap_setgid", "cap_setuid", "cap_setpcap",
+	"cap_linux_immutable", "cap_net_bind_service", "cap_net_broadcast",
+	"cap_net_admin", "cap_net_raw", "cap_ipc_lock", "cap_ipc_owner",
+	"cap_sys_module", "cap_sys_rawio", "cap_sys_chroot", "cap_sys_ptrace",
+	"cap_sys_pacct", "cap_sys_admin", "cap_sys_boot", "cap_sys_nice",
+	"cap_sys_resource", "cap_sys_time", "cap_sys_tty_config", "cap_mknod",
+	"cap_lease", "cap_audit_write", "cap_audit_control", "cap_setfcap",
+	"cap_mac_override", "cap_mac_admin", "cap_syslog", "cap_wake_alarm",
+	"cap_block_suspend", "cap_audit_read", "cap_perfmon", "cap_bpf",
+	"cap_checkpoint_restore",
+}
+
+// capSets contains names of capability sets and names of fields with these
+// sets in /proc/<pid>/status
+var capSets = map[string]string{
+	"eff": "CapEff",
+	"prm": "CapPrm",
+	"inh": "CapInh",
+	"bnd": "CapBnd",
+	"amb": "CapAmb",
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// ProcUID is action processor for "proc-uid"
+func ProcUID(action *recipe.Action, cmd *exec.Cmd) error {
+	username, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	uid, err := lookupUID(username)
+
+	if err != nil {
+		return err
+	}
+
+	procUID, err := getProcStatusID(pid, "Uid")
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !action.Negative && procUID != uid:
+		return fmt.Errorf(
+			"Process %d has different effective UID (%d ≠ %d)",
+			pid, procUID, uid,
+		)
+	case action.Negative && procUID == uid:
+		return fmt.Errorf("Process %d has invalid effective UID (%d)", pid, uid)
+	}
+
+	return nil
+}
+
+// ProcGID is action processor for "proc-gid"
+func ProcGID(action *recipe.Action, cmd *exec.Cmd) error {
+	groupname, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	gid, err := lookupGID(groupname)
+
+	if err != nil {
+		return err
+	}
+
+	procGID, err := getProcStatusID(pid, "Gid")
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !action.Negative && procGID != gid:
+		return fmt.Errorf(
+			"Process %d has different effective GID (%d ≠ %d)",
+			pid, procGID, gid,
+		)
+	case action.Negative && procGID == gid:
+		return fmt.Errorf("Process %d has invalid effective GID (%d)", pid, gid)
+	}
+
+	return nil
+}
+
+// ProcGroup is action processor for "proc-group"
+func ProcGroup(action *recipe.Action, cmd *exec.Cmd) error {
+	groupname, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	gid, err := lookupGID(groupname)
+
+	if err != nil {
+		return err
+	}
+
+	groups, err := readProcStatusField(pid, "Groups")
+
+	if err != nil {
+		return err
+	}
+
+	hasGroup := slices.Contains(strings.Fields(groups), strconv.Itoa(gid))
+
+	switch {
+	case !action.Negative && !hasGroup:
+		return fmt.Errorf("Process %d doesn't have supplementary group %s", pid, groupname)
+	case action.Negative && hasGroup:
+		return fmt.Errorf("Process %d has supplementary group %s", pid, groupname)
+	}
+
+	return nil
+}
+
+// ProcCap is action processor for "proc-cap"
+func ProcCap(action *recipe.Action, cmd *exec.Cmd) error {
+	set, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	capName, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 2, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	field := capSets[set]
+
+	if field == "" {
+		return fmt.Errorf("Unknown capability set %q (must be eff, prm, inh, bnd or amb)", set)
+	}
+
+	capNum, err := parseCapName(capName)
+
+	if err != nil {
+		return err
+	}
+
+	capsHex, err := readProcStatusField(pid, field)
+
+	if err != nil {
+		return err
+	}
+
+	caps, err := strconv.ParseUint(capsHex, 16, 64)
+
+	if err != nil {
+		return fmt.Errorf("Can't parse capabilities of process %d: %v", pid, err)
+	}
+
+	hasCap := caps&(1<<capNum) != 0
+
+	switch {
+	case !action.Negative && !hasCap:
+		return fmt.Errorf(
+			"Process %d doesn't have capability %s in set %s",
+			pid, capNames[capNum], set,
+		)
+	case action.Negative && hasCap:
+		return fmt.Errorf(
+			"Process %d has capability %s in set %s",
+			pid, capNames[capNum], set,
+		)
+	}
+
+	return nil
+}
+
+// ProcEnv is action processor for "proc-env"
+func ProcEnv(action *recipe.Action, cmd *exec.Cmd) error {
+	name, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	value, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 2, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+
+	if err != nil {
+		return fmt.Errorf("Can't read environment of process %d: %v", pid, err)
+	}
+
+	var procValue string
+	var isSet bool
+
+	for _, v := range strings.Split(string(data), "\x00") {
+		if strings.HasPrefix(v, name+"=") {
+			procValue, isSet = strings.TrimPrefix(v, name+"="), true
+		}
+	}
+
+	switch {
+	case !action.Negative && !isSet:
+		return fmt.Errorf("Process %d doesn't have environment variable %s", pid, name)
+	case !action.Negative && procValue != value:
+		return fmt.Errorf(
+			"Process %d has different value of environment variable %s (%s ≠ %s)",
+			pid, name, procValue, value,
+		)
+	case action.Negative && isSet && procValue == value:
+		return fmt.Errorf(
+			"Process %d has invalid value of environment variable %s (%s)",
+			pid, name, value,
+		)
+	}
+
+	return nil
+}
+
+// ProcCwd is action processor for "proc-cwd"
+func ProcCwd(action *recipe.Action, cmd *exec.Cmd) error {
+	dir, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	procDir, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid))
+
+	if err != nil {
+		return fmt.Errorf("Can't read working directory of process %d: %v", pid, err)
+	}
+
+	dir = filepath.Clean(dir)
+
+	switch {
+	case !action.Negative && procDir != dir:
+		return fmt.Errorf(
+			"Process %d has different working directory (%s ≠ %s)",
+			pid, procDir, dir,
+		)
+	case action.Negative && procDir == dir:
+		return fmt.Errorf("Process %d has invalid working directory (%s)", pid, dir)
+	}
+
+	return nil
+}
+
+// ProcUmask is action processor for "proc-umask"
+func ProcUmask(action *recipe.Action, cmd *exec.Cmd) error {
+	umaskStr, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	umask, err := strconv.ParseUint(umaskStr, 8, 32)
+
+	if err != nil || umask > 0777 {
+		return fmt.Errorf("Can't parse umask %q", umaskStr)
+	}
+
+	procUmaskStr, err := readProcStatusField(pid, "Umask")
+
+	if err != nil {
+		return err
+	}
+
+	procUmask, err := strconv.ParseUint(procUmaskStr, 8, 32)
+
+	if err != nil {
+		return fmt.Errorf("Can't parse umask of process %d: %v", pid, err)
+	}
+
+	switch {
+	case !action.Negative && procUmask != umask:
+		return fmt.Errorf(
+			"Process %d has different umask (%04o ≠ %04o)",
+			pid, procUmask, umask,
+		)
+	case action.Negative && procUmask == umask:
+		return fmt.Errorf("Process %d has invalid umask (%04o)", pid, umask)
+	}
+
+	return nil
+}
+
+// ProcNice is action processor for "proc-nice"
+func ProcNice(action *recipe.Action, cmd *exec.Cmd) error {
+	nice, err := action.GetI(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+
+	if err != nil {
+		return fmt.Errorf("Can't read info about process %d: %v", pid, err)
+	}
+
+	// Process name can contain spaces, so we have to skip it first. Nice value
+	// is 19th field, and the 3rd field is the first one after the name.
+	stat := string(data)
+	fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+
+	if len(fields) < 17 {
+		return fmt.Errorf("Can't parse info about process %d", pid)
+	}
+
+	procNice, err := strconv.Atoi(fields[16])
+
+	if err != nil {
+		return fmt.Errorf("Can't parse nice value of process %d: %v", pid, err)
+	}
+
+	switch {
+	case !action.Negative && procNice != nice:
+		return fmt.Errorf(
+			"Process %d has different nice value (%d ≠ %d)",
+			pid, procNice, nice,
+		)
+	case action.Negative && procNice == nice:
+		return fmt.Errorf("Process %d has invalid nice value (%d)", pid, nice)
+	}
+
+	return nil
+}
+
+// ProcCgroup is action processor for "proc-cgroup"
+func ProcCgroup(action *recipe.Action, cmd *exec.Cmd) error {
+	cgroup, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+
+	if err != nil {
+		return fmt.Errorf("Can't read cgroup info of process %d: %v", pid, err)
+	}
+
+	var inCgroup bool
+
+	for _, line := range strings.Split(string(data), "\n") {
+		// Format is hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(line, ":", 3)
+
+		if len(fields) == 3 && fields[2] == cgroup {
+			inCgroup = true
+			break
+		}
+	}
+
+	switch {
+	case !action.Negative && !inCgroup:
+		return fmt.Errorf("Process %d is not a member of cgroup %s", pid, cgroup)
+	case action.Negative && inCgroup:
+		return fmt.Errorf("Process %d is a member of cgroup %s", pid, cgroup)
+	}
+
+	return nil
+}
+
+// ProcExe is action processor for "proc-exe"
+func ProcExe(action *recipe.Action, cmd *exec.Cmd) error {
+	exe, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	pid, err := getTargetPID(action, 1, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	procExe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+
+	if err != nil {
+		return fmt.Errorf("Can't read executable path of process %d: %v", pid, err)
+	}
+
+	exe = filepath.Clean(exe)
+
+	switch {
+	case !action.Negative && procExe != exe:
+		return fmt.Errorf(
+			"Process %d has different executable (%s ≠ %s)",
+			pid, procExe, exe,
+		)
+	case action.Negative && procExe == exe:
+		return fmt.Errorf("Process %d has invalid executable (%s)", pid, exe)
+	}
+
+	return nil
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// readProcStatusField reads field with given name from /proc/<pid>/status
+func readProcStatusField(pid int, field string) (string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+
+	if err != nil {
+		return "", fmt.Errorf("Can't read info about process %d: %v", pid, err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		name, value, ok := strings.Cut(line, ":")
+
+		if ok && name == field {
+			return strings.TrimSpace(value), nil
+		}
+	}
+
+	return "", fmt.Errorf("Can't find field %s in info about process %d", field, pid)
+}
+
+// getProcStatusID returns effective ID from field with UIDs or GIDs
+func getProcStatusID(pid int, field string) (int, error) {
+	value, err := readProcStatusField(pid, field)
+
+	if err != nil {
+		return -1, err
+	}
+
+	// Field contains real, effective, saved set and filesystem IDs
+	fields := strings.Fields(value)
+
+	if len(fields) < 2 {
+		return -1, fmt.Errorf("Can't parse field %s in info about process %d", field, pid)
+	}
+
+	return strconv.Atoi(fields[1])
+}
+
+// parseCapName returns number of capability with given name
+func parseCapName(name string) (int, error) {
+	name = strings.ToLower(name)
+
+	if !strings.HasPrefix(name, "cap_") {
+		name = "cap_" + name
+	}
+
+	index := slices.Index(capNames, name)
+
+	if index == -1 {
+		return -1, fmt.Errorf("Unknown capability %q", name)
+	}
+
+	return index, nil
+}
+
+// lookupGID returns GID of group with given name or ID
+func lookupGID(nameOrID string) (int, error) {
+	group, err := system.LookupGroup(nameOrID)
+
+	if err == nil {
+		return group.GID, nil
+	}
+
+	if isNumber(nameOrID) {
+		return strconv.Atoi(nameOrID)
+	}
+
+	return -1, fmt.Errorf("Group %s doesn't exist on the system", nameOrID)
+}
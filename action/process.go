@@ -0,0 +1,585 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/fsutil"
+	"github.com/essentialkaos/ek/v13/mathutil"
+	"github.com/essentialkaos/ek/v13/pid"
+	"github.com/essentialkaos/ek/v13/system"
+	"github.com/essentialkaos/ek/v13/timeutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const (
+	PROCESS_SELECTOR_NAME    = "name"
+	PROCESS_SELECTOR_CMDLINE = "cmdline"
+	PROCESS_SELECTOR_USER    = "user"
+	PROCESS_SELECTOR_PID     = "pid"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// processInfo contains basic info about process
+type processInfo struct {
+	Name    string
+	Exe     string
+	Cmdline string
+	State   string
+	PID     int
+	PPID    int
+	UID     int
+}
+
+// processSelector is process filter
+type processSelector struct {
+	Type    string
+	Pattern string
+	Regexp  *regexp.Regexp
+	UID     int
+	PID     int
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// processStates contains names of process states
+var processStates = map[string]string{
+	"R": "running",
+	"S": "sleeping",
+	"D": "disk-sleep",
+	"Z": "zombie",
+	"T": "stopped",
+	"t": "tracing-stop",
+	"X": "dead",
+	"I": "idle",
+	"P": "parked",
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// ProcessExist is action processor for "process-exist"
+func ProcessExist(action *recipe.Action) error {
+	selector, err := getProcessSelector(action)
+
+	if err != nil {
+		return err
+	}
+
+	processes, err := findProcesses(selector)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !action.Negative && len(processes) == 0:
+		return fmt.Errorf("There is no process with %s", selector)
+	case action.Negative && len(processes) != 0:
+		return fmt.Errorf(
+			"There is process with %s (PID: %d)",
+			selector, processes[0].PID,
+		)
+	}
+
+	return nil
+}
+
+// ProcessCount is action processor for "process-count"
+func ProcessCount(action *recipe.Action) error {
+	selector, err := getProcessSelector(action)
+
+	if err != nil {
+		return err
+	}
+
+	count, err := action.GetI(2)
+
+	if err != nil {
+		return err
+	}
+
+	if count < 0 {
+		return fmt.Errorf("Number of processes can't be less than 0 (%d)", count)
+	}
+
+	processes, err := findProcesses(selector)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !action.Negative && len(processes) != count:
+		return fmt.Errorf(
+			"Number of processes with %s is different (%d ≠ %d)",
+			selector, len(processes), count,
+		)
+	case action.Negative && len(processes) == count:
+		return fmt.Errorf(
+			"Number of processes with %s is equal to %d",
+			selector, count,
+		)
+	}
+
+	return nil
+}
+
+// ProcessUser is action processor for "process-user"
+func ProcessUser(action *recipe.Action) error {
+	selector, err := getProcessSelector(action)
+
+	if err != nil {
+		return err
+	}
+
+	username, err := action.GetS(2)
+
+	if err != nil {
+		return err
+	}
+
+	uid, err := lookupUID(username)
+
+	if err != nil {
+		return err
+	}
+
+	processes, err := findRequiredProcesses(selector)
+
+	if err != nil {
+		return err
+	}
+
+	for _, p := range processes {
+		switch {
+		case !action.Negative && p.UID != uid:
+			return fmt.Errorf(
+				"Process %d with %s works as user %s",
+				p.PID, selector, getUsername(p.UID),
+			)
+		case action.Negative && p.UID == uid:
+			return fmt.Errorf(
+				"Process %d with %s works as user %s",
+				p.PID, selector, username,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ProcessParent is action processor for "process-parent"
+func ProcessParent(action *recipe.Action) error {
+	selector, err := getProcessSelector(action)
+
+	if err != nil {
+		return err
+	}
+
+	parent, err := action.GetS(2)
+
+	if err != nil {
+		return err
+	}
+
+	ppid, err := parsePID(parent)
+
+	if err != nil {
+		return err
+	}
+
+	processes, err := findRequiredProcesses(selector)
+
+	if err != nil {
+		return err
+	}
+
+	for _, p := range processes {
+		switch {
+		case !action.Negative && p.PPID != ppid:
+			return fmt.Errorf(
+				"Process %d with %s has different parent PID (%d ≠ %d)",
+				p.PID, selector, p.PPID, ppid,
+			)
+		case action.Negative && p.PPID == ppid:
+			return fmt.Errorf(
+				"Process %d with %s has parent PID %d",
+				p.PID, selector, ppid,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ProcessState is action processor for "process-state"
+func ProcessState(action *recipe.Action) error {
+	selector, err := getProcessSelector(action)
+
+	if err != nil {
+		return err
+	}
+
+	state, err := action.GetS(2)
+
+	if err != nil {
+		return err
+	}
+
+	state, err = parseProcessState(state)
+
+	if err != nil {
+		return err
+	}
+
+	processes, err := findRequiredProcesses(selector)
+
+	if err != nil {
+		return err
+	}
+
+	for _, p := range processes {
+		switch {
+		case !action.Negative && p.State != state:
+			return fmt.Errorf(
+				"Process %d with %s has different state (%s ≠ %s)",
+				p.PID, selector, formatProcessState(p.State),
+				formatProcessState(state),
+			)
+		case action.Negative && p.State == state:
+			return fmt.Errorf(
+				"Process %d with %s has state %s",
+				p.PID, selector, formatProcessState(state),
+			)
+		}
+	}
+
+	return nil
+}
+
+// WaitProcess is action processor for "wait-process"
+func WaitProcess(action *recipe.Action) error {
+	var timeout float64
+
+	selector, err := getProcessSelector(action)
+
+	if err != nil {
+		return err
+	}
+
+	if action.Has(2) {
+		timeout, err = action.GetF(2)
+
+		if err != nil {
+			return err
+		}
+	} else {
+		timeout = 60.0
+	}
+
+	start := time.Now()
+	timeout = mathutil.Between(timeout, 0.01, 3600.0)
+	timeoutDur := timeutil.SecondsToDuration(timeout)
+
+	for sleep(action, 25*time.Millisecond) {
+		processes, err := findProcesses(selector)
+
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case !action.Negative && len(processes) != 0,
+			action.Negative && len(processes) == 0:
+			return nil
+		}
+
+		if time.Since(start) >= timeoutDur {
+			break
+		}
+	}
+
+	if isAborted(action) {
+		return ErrAborted
+	}
+
+	switch action.Negative {
+	case false:
+		return fmt.Errorf(
+			"Timeout (%g sec) reached, and process with %s didn't appear",
+			timeout, selector,
+		)
+	default:
+		return fmt.Errorf(
+			"Timeout (%g sec) reached, and process with %s still works",
+			timeout, selector,
+		)
+	}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// String returns description of selector
+func (s *processSelector) String() string {
+	switch s.Type {
+	case PROCESS_SELECTOR_NAME:
+		return fmt.Sprintf("name %q", s.Pattern)
+	case PROCESS_SELECTOR_CMDLINE:
+		return fmt.Sprintf("cmdline matching %q", s.Pattern)
+	case PROCESS_SELECTOR_PID:
+		return fmt.Sprintf("PID %d", s.PID)
+	}
+
+	return fmt.Sprintf("user %q", s.Pattern)
+}
+
+// IsMatch returns true if process matches selector
+func (s *processSelector) IsMatch(p *processInfo) bool {
+	switch s.Type {
+	case PROCESS_SELECTOR_NAME:
+		return p.Name == s.Pattern ||
+			(p.Exe != "" && filepath.Base(p.Exe) == s.Pattern) ||
+			(p.Cmdline != "" && filepath.Base(strings.Fields(p.Cmdline)[0]) == s.Pattern)
+	case PROCESS_SELECTOR_CMDLINE:
+		return p.Cmdline != "" && s.Regexp.MatchString(p.Cmdline)
+	case PROCESS_SELECTOR_PID:
+		return p.PID == s.PID
+	}
+
+	return p.UID == s.UID
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getProcessSelector creates process selector using first two action arguments
+func getProcessSelector(action *recipe.Action) (*processSelector, error) {
+	return getProcessSelectorAt(action, 0)
+}
+
+// getProcessSelectorAt creates process selector using two action arguments
+// starting from given index
+func getProcessSelectorAt(action *recipe.Action, index int) (*processSelector, error) {
+	selectorType, err := action.GetS(index)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := action.GetS(index + 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	selector := &processSelector{Type: selectorType, Pattern: pattern}
+
+	switch selectorType {
+	case PROCESS_SELECTOR_NAME:
+		// nothing to do
+	case PROCESS_SELECTOR_CMDLINE:
+		selector.Regexp, err = regexp.Compile(pattern)
+
+		if err != nil {
+			return nil, fmt.Errorf("Can't compile regular expression %q: %v", pattern, err)
+		}
+	case PROCESS_SELECTOR_USER:
+		selector.UID, err = lookupUID(pattern)
+
+		if err != nil {
+			return nil, err
+		}
+	case PROCESS_SELECTOR_PID:
+		selector.PID, err = parsePID(pattern)
+
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf(
+			"Unknown process selector %q (must be %q, %q, %q or %q)",
+			selectorType, PROCESS_SELECTOR_NAME, PROCESS_SELECTOR_CMDLINE,
+			PROCESS_SELECTOR_USER, PROCESS_SELECTOR_PID,
+		)
+	}
+
+	return selector, nil
+}
+
+// findRequiredProcesses returns processes matching selector or error if there
+// are no such processes
+func findRequiredProcesses(selector *processSelector) ([]*processInfo, error) {
+	processes, err := findProcesses(selector)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(processes) == 0 {
+		return nil, fmt.Errorf("There is no process with %s", selector)
+	}
+
+	return processes, nil
+}
+
+// findProcesses returns all processes matching selector. Current process is
+// always ignored.
+func findProcesses(selector *processSelector) ([]*processInfo, error) {
+	var result []*processInfo
+
+	dirs, err := os.ReadDir("/proc")
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read processes info: %v", err)
+	}
+
+	selfPID := os.Getpid()
+
+	for _, dir := range dirs {
+		if !dir.IsDir() || !isNumber(dir.Name()) {
+			continue
+		}
+
+		p := readProcessInfo(dir.Name())
+
+		// Process may finish while we are reading info about it
+		if p == nil || p.PID == selfPID {
+			continue
+		}
+
+		if selector.IsMatch(p) {
+			result = append(result, p)
+		}
+	}
+
+	return result, nil
+}
+
+// readProcessInfo reads info about process from procfs
+func readProcessInfo(pidStr string) *processInfo {
+	procDir := "/proc/" + pidStr
+	data, err := os.ReadFile(procDir + "/status")
+
+	if err != nil {
+		return nil
+	}
+
+	p := &processInfo{}
+	p.PID, _ = strconv.Atoi(pidStr)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		name, value, ok := strings.Cut(line, ":")
+
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "Name":
+			p.Name = value
+		case "State":
+			p.State, _, _ = strings.Cut(value, " ")
+		case "PPid":
+			p.PPID, _ = strconv.Atoi(value)
+		case "Uid":
+			// Effective UID is the second field
+			fields := strings.Fields(value)
+
+			if len(fields) > 1 {
+				p.UID, _ = strconv.Atoi(fields[1])
+			}
+		}
+	}
+
+	cmdline, _ := os.ReadFile(procDir + "/cmdline")
+	p.Cmdline = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	p.Exe, _ = os.Readlink(procDir + "/exe")
+
+	return p
+}
+
+// parsePID parses PID from number or PID file
+func parsePID(v string) (int, error) {
+	if isNumber(v) {
+		return strconv.Atoi(v)
+	}
+
+	if !fsutil.IsExist(v) {
+		return -1, fmt.Errorf("PID must be a number or path to PID file")
+	}
+
+	ppid := pid.Read(v)
+
+	if ppid == -1 {
+		return -1, ErrCantReadPIDFile
+	}
+
+	return ppid, nil
+}
+
+// parseProcessState parses process state name or code
+func parseProcessState(v string) (string, error) {
+	if processStates[v] != "" {
+		return v, nil
+	}
+
+	for code, name := range processStates {
+		if strings.EqualFold(name, v) {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unknown process state %q", v)
+}
+
+// formatProcessState returns process state with name
+func formatProcessState(state string) string {
+	if processStates[state] == "" {
+		return state
+	}
+
+	return state + " (" + processStates[state] + ")"
+}
+
+// lookupUID returns UID of user with given name or ID
+func lookupUID(nameOrID string) (int, error) {
+	user, err := system.LookupUser(nameOrID)
+
+	if err == nil {
+		return user.UID, nil
+	}
+
+	if isNumber(nameOrID) {
+		return strconv.Atoi(nameOrID)
+	}
+
+	return -1, fmt.Errorf("User %s doesn't exist on the system", nameOrID)
+}
+
+// getUsername returns name of user with given UID
+func getUsername(uid int) string {
+	user, err := system.LookupUser(strconv.Itoa(uid))
+
+	if err != nil {
+		return strconv.Itoa(uid)
+	}
+
+	return user.Name
+}
@@ -112,7 +112,7 @@ func WaitService(action *recipe.Action) error {
 	timeout = mathutil.Between(timeout, 0.01, 3600.0)
 	timeoutDur := timeutil.SecondsToDuration(timeout)
 
-	for range time.NewTicker(time.Second / 2).C {
+	for sleep(action, time.Second/2) {
 		isWorks, err := initsystem.IsWorks(service)
 
 		if err == nil {
@@ -128,6 +128,10 @@ func WaitService(action *recipe.Action) error {
 		}
 	}
 
+	if isAborted(action) {
+		return ErrAborted
+	}
+
 	switch action.Negative {
 	case false:
 		return fmt.Errorf(
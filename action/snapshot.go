@@ -0,0 +1,320 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/essentialkaos/ek/v13/hashutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const PROP_SNAPSHOT = "SNAPSHOT"
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const (
+	_SNAPSHOT_CREATED = "created"
+	_SNAPSHOT_DELETED = "deleted"
+	_SNAPSHOT_CHANGED = "changed"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// fsSnapshot contains info about all objects in directory tree
+type fsSnapshot struct {
+	Root    string
+	Objects map[string]*fsObject
+}
+
+// fsObject contains info about filesystem object
+type fsObject struct {
+	Type string
+	Hash string
+	Size int64
+	Mode os.FileMode
+	UID  int
+	GID  int
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Snapshot is action processor for "snapshot"
+func Snapshot(action *recipe.Action) error {
+	name, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	dir, err := action.GetS(1)
+
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := makeSnapshot(dir)
+
+	if err != nil {
+		return err
+	}
+
+	action.Command.Recipe.Data.Set(PROP_SNAPSHOT+":"+name, snapshot)
+
+	return nil
+}
+
+// SnapshotCreated is action processor for "snapshot-created"
+func SnapshotCreated(action *recipe.Action) error {
+	return checkSnapshotDiff(action, _SNAPSHOT_CREATED)
+}
+
+// SnapshotDeleted is action processor for "snapshot-deleted"
+func SnapshotDeleted(action *recipe.Action) error {
+	return checkSnapshotDiff(action, _SNAPSHOT_DELETED)
+}
+
+// SnapshotChanged is action processor for "snapshot-changed"
+func SnapshotChanged(action *recipe.Action) error {
+	return checkSnapshotDiff(action, _SNAPSHOT_CHANGED)
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Equal returns true if objects are equal
+func (o *fsObject) Equal(oo *fsObject) bool {
+	return o.Type == oo.Type && o.Hash == oo.Hash && o.Size == oo.Size &&
+		o.Mode == oo.Mode && o.UID == oo.UID && o.GID == oo.GID
+}
+
+// relPath converts given path to path relative to snapshot root
+func (s *fsSnapshot) relPath(path string) string {
+	absPath, err := filepath.Abs(path)
+
+	if err != nil {
+		return path
+	}
+
+	relPath, err := filepath.Rel(s.Root, absPath)
+
+	if err != nil {
+		return path
+	}
+
+	return relPath
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// checkSnapshotDiff compares snapshot with current state of directory tree
+func checkSnapshotDiff(action *recipe.Action, kind string) error {
+	name, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	snapshot, ok := action.Command.Recipe.Data.Get(PROP_SNAPSHOT + ":" + name).(*fsSnapshot)
+
+	if !ok {
+		return fmt.Errorf("Snapshot %q doesn't exist", name)
+	}
+
+	var paths []string
+
+	for index := 1; index < len(action.Arguments); index++ {
+		path, err := action.GetS(index)
+
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, snapshot.relPath(path))
+	}
+
+	if action.Negative && len(paths) == 0 {
+		return fmt.Errorf("Negative form of action %q requires at least one path", action.Name)
+	}
+
+	current, err := makeSnapshot(snapshot.Root)
+
+	if err != nil {
+		return err
+	}
+
+	diff := getSnapshotDiff(snapshot, current, kind)
+
+	if action.Negative {
+		var found []string
+
+		for _, path := range paths {
+			if slices.Contains(diff, path) {
+				found = append(found, path)
+			}
+		}
+
+		if len(found) != 0 {
+			return fmt.Errorf(
+				"Objects in %s were %s: %s",
+				snapshot.Root, kind, strings.Join(found, ", "),
+			)
+		}
+
+		return nil
+	}
+
+	var unexpected, missing []string
+
+	for _, path := range diff {
+		if !slices.Contains(paths, path) {
+			unexpected = append(unexpected, path)
+		}
+	}
+
+	for _, path := range paths {
+		if !slices.Contains(diff, path) {
+			missing = append(missing, path)
+		}
+	}
+
+	switch {
+	case len(unexpected) != 0:
+		return fmt.Errorf(
+			"Objects in %s were unexpectedly %s: %s",
+			snapshot.Root, kind, strings.Join(unexpected, ", "),
+		)
+	case len(missing) != 0:
+		return fmt.Errorf(
+			"Objects in %s were not %s: %s",
+			snapshot.Root, kind, strings.Join(missing, ", "),
+		)
+	}
+
+	return nil
+}
+
+// getSnapshotDiff returns sorted slice with paths of created, deleted or changed
+// objects
+func getSnapshotDiff(prev, cur *fsSnapshot, kind string) []string {
+	var result []string
+
+	switch kind {
+	case _SNAPSHOT_CREATED:
+		for path := range cur.Objects {
+			if prev.Objects[path] == nil {
+				result = append(result, path)
+			}
+		}
+
+	case _SNAPSHOT_DELETED:
+		for path := range prev.Objects {
+			if cur.Objects[path] == nil {
+				result = append(result, path)
+			}
+		}
+
+	case _SNAPSHOT_CHANGED:
+		for path, obj := range cur.Objects {
+			if prev.Objects[path] != nil && !prev.Objects[path].Equal(obj) {
+				result = append(result, path)
+			}
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// makeSnapshot creates snapshot of directory tree
+func makeSnapshot(dir string) (*fsSnapshot, error) {
+	root, err := filepath.Abs(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(root)
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't create snapshot of %s: %v", dir, err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("Can't create snapshot of %s: object is not a directory", dir)
+	}
+
+	snapshot := &fsSnapshot{Root: root, Objects: make(map[string]*fsObject)}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		obj, err := getFSObjectInfo(path)
+
+		if err != nil {
+			return err
+		}
+
+		relPath, _ := filepath.Rel(root, path)
+		snapshot.Objects[relPath] = obj
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't create snapshot of %s: %v", dir, err)
+	}
+
+	return snapshot, nil
+}
+
+// getFSObjectInfo collects info about filesystem object
+func getFSObjectInfo(path string) (*fsObject, error) {
+	info, err := os.Lstat(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &fsObject{Mode: info.Mode()}
+
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		obj.UID, obj.GID = int(stat.Uid), int(stat.Gid)
+	}
+
+	switch {
+	case info.Mode().IsRegular():
+		obj.Type, obj.Size = "file", info.Size()
+		obj.Hash = hashutil.File(path, sha256.New()).String()
+	case info.IsDir():
+		obj.Type = "dir"
+	case info.Mode()&os.ModeSymlink != 0:
+		obj.Type = "link"
+		obj.Hash, err = os.Readlink(path)
+	default:
+		obj.Type = "other"
+	}
+
+	return obj, err
+}
@@ -0,0 +1,415 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// socketInfo contains info about listening socket
+type socketInfo struct {
+	Proto string
+	IP    net.IP
+	Port  int
+	Path  string
+	Inode string
+}
+
+// socketAddress contains address of listening socket
+type socketAddress struct {
+	IP   net.IP // nil means any address
+	Port int
+	Path string
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// socketFiles contains procfs files with info about sockets of each protocol
+var socketFiles = map[string][]string{
+	"tcp":  {"tcp", "tcp6"},
+	"tcp4": {"tcp"},
+	"tcp6": {"tcp6"},
+	"udp":  {"udp", "udp6"},
+	"udp4": {"udp"},
+	"udp6": {"udp6"},
+	"unix": {"unix"},
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Listen is action processor for "listen"
+func Listen(action *recipe.Action) error {
+	proto, addr, err := getSocketProtoAndAddress(action)
+
+	if err != nil {
+		return err
+	}
+
+	sockets, err := findSockets(proto, addr)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !action.Negative && len(sockets) == 0:
+		return fmt.Errorf("There is no %s socket listening on %s", proto, formatSocketAddress(addr))
+	case action.Negative && len(sockets) != 0:
+		return fmt.Errorf("There is %s socket listening on %s", proto, formatSocketAddress(addr))
+	}
+
+	return nil
+}
+
+// ListenOwner is action processor for "listen-owner"
+func ListenOwner(action *recipe.Action) error {
+	proto, addr, err := getSocketProtoAndAddress(action)
+
+	if err != nil {
+		return err
+	}
+
+	selector, err := getProcessSelectorAt(action, 2)
+
+	if err != nil {
+		return err
+	}
+
+	sockets, err := findSockets(proto, addr)
+
+	if err != nil {
+		return err
+	}
+
+	if len(sockets) == 0 {
+		return fmt.Errorf("There is no %s socket listening on %s", proto, formatSocketAddress(addr))
+	}
+
+	owners, err := findSocketOwners(sockets)
+
+	if err != nil {
+		return err
+	}
+
+	isOwned := false
+
+	for _, p := range owners {
+		if selector.IsMatch(p) {
+			isOwned = true
+			break
+		}
+	}
+
+	switch {
+	case !action.Negative && !isOwned:
+		return fmt.Errorf(
+			"%s socket listening on %s is not owned by process with %s",
+			strings.ToUpper(proto), formatSocketAddress(addr), selector,
+		)
+	case action.Negative && isOwned:
+		return fmt.Errorf(
+			"%s socket listening on %s is owned by process with %s",
+			strings.ToUpper(proto), formatSocketAddress(addr), selector,
+		)
+	}
+
+	return nil
+}
+
+// ListenLocal is action processor for "listen-local"
+func ListenLocal(action *recipe.Action) error {
+	proto, err := action.GetS(0)
+
+	if err != nil {
+		return err
+	}
+
+	if socketFiles[proto] == nil || proto == "unix" {
+		return fmt.Errorf("Unsupported protocol %q", proto)
+	}
+
+	port, err := action.GetI(1)
+
+	if err != nil {
+		return err
+	}
+
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("Invalid port number (%d)", port)
+	}
+
+	sockets, err := findSockets(proto, &socketAddress{Port: port})
+
+	if err != nil {
+		return err
+	}
+
+	if len(sockets) == 0 {
+		return fmt.Errorf("There is no %s socket listening on port %d", proto, port)
+	}
+
+	for _, s := range sockets {
+		if !s.IP.IsLoopback() {
+			return fmt.Errorf(
+				"%s socket on port %d is listening on non-loopback address %s",
+				strings.ToUpper(proto), port, s.IP,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getSocketProtoAndAddress parses protocol and address from first two action
+// arguments
+func getSocketProtoAndAddress(action *recipe.Action) (string, *socketAddress, error) {
+	proto, err := action.GetS(0)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if socketFiles[proto] == nil {
+		return "", nil, fmt.Errorf("Unsupported protocol %q", proto)
+	}
+
+	addr, err := action.GetS(1)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if proto == "unix" {
+		return proto, &socketAddress{Path: addr}, nil
+	}
+
+	host, portStr, err := net.SplitHostPort(addr)
+
+	if err != nil {
+		return "", nil, fmt.Errorf("Can't parse address %q: %v", addr, err)
+	}
+
+	port, err := strconv.Atoi(portStr)
+
+	if err != nil || port <= 0 || port > 65535 {
+		return "", nil, fmt.Errorf("Invalid port number in address %q", addr)
+	}
+
+	result := &socketAddress{Port: port}
+
+	if host != "" && host != "*" {
+		result.IP = net.ParseIP(host)
+
+		if result.IP == nil {
+			return "", nil, fmt.Errorf("Can't parse IP in address %q", addr)
+		}
+	}
+
+	return proto, result, nil
+}
+
+// findSockets returns all listening sockets matching given address
+func findSockets(proto string, addr *socketAddress) ([]*socketInfo, error) {
+	var result []*socketInfo
+
+	for _, file := range socketFiles[proto] {
+		sockets, err := readSockets(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range sockets {
+			switch {
+			case addr.Path != "" && s.Path != addr.Path,
+				addr.Port != 0 && s.Port != addr.Port,
+				addr.IP != nil && !addr.IP.Equal(s.IP):
+				continue
+			}
+
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+// readSockets reads info about listening sockets from procfs file
+func readSockets(file string) ([]*socketInfo, error) {
+	var result []*socketInfo
+
+	data, err := os.ReadFile("/proc/net/" + file)
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read sockets info: %v", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+
+	// Skip header
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+
+		var s *socketInfo
+
+		if file == "unix" {
+			s = parseUnixSocketInfo(fields)
+		} else {
+			s = parseInetSocketInfo(file, fields)
+		}
+
+		if s != nil {
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+// parseInetSocketInfo parses info about TCP or UDP socket
+func parseInetSocketInfo(file string, fields []string) *socketInfo {
+	if len(fields) < 10 {
+		return nil
+	}
+
+	// TCP sockets must be in LISTEN state (0A) and UDP sockets must be
+	// unconnected (07)
+	switch {
+	case strings.HasPrefix(file, "tcp") && fields[3] != "0A",
+		strings.HasPrefix(file, "udp") && fields[3] != "07":
+		return nil
+	}
+
+	hexIP, hexPort, ok := strings.Cut(fields[1], ":")
+
+	if !ok {
+		return nil
+	}
+
+	ip := parseHexIP(hexIP)
+	port, err := strconv.ParseInt(hexPort, 16, 32)
+
+	if ip == nil || err != nil {
+		return nil
+	}
+
+	return &socketInfo{
+		Proto: file,
+		IP:    ip,
+		Port:  int(port),
+		Inode: fields[9],
+	}
+}
+
+// parseUnixSocketInfo parses info about unix socket
+func parseUnixSocketInfo(fields []string) *socketInfo {
+	if len(fields) < 8 {
+		return nil
+	}
+
+	flags, err := strconv.ParseUint(fields[3], 16, 32)
+
+	if err != nil {
+		return nil
+	}
+
+	// Stream sockets must have __SO_ACCEPTCON flag, and datagram sockets
+	// just must be bound
+	if flags&0x10000 == 0 && fields[4] != "0002" {
+		return nil
+	}
+
+	return &socketInfo{
+		Proto: "unix",
+		Path:  fields[7],
+		Inode: fields[6],
+	}
+}
+
+// parseHexIP parses IP address from procfs format
+func parseHexIP(v string) net.IP {
+	data, err := hex.DecodeString(v)
+
+	if err != nil || (len(data) != net.IPv4len && len(data) != net.IPv6len) {
+		return nil
+	}
+
+	// Address is stored as a sequence of 32-bit words in host byte order
+	for i := 0; i < len(data); i += 4 {
+		data[i], data[i+1], data[i+2], data[i+3] = data[i+3], data[i+2], data[i+1], data[i]
+	}
+
+	return net.IP(data)
+}
+
+// findSocketOwners returns processes which have descriptors of given sockets
+func findSocketOwners(sockets []*socketInfo) ([]*processInfo, error) {
+	var result []*processInfo
+
+	links := make(map[string]bool)
+
+	for _, s := range sockets {
+		links["socket:["+s.Inode+"]"] = true
+	}
+
+	dirs, err := os.ReadDir("/proc")
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read processes info: %v", err)
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() || !isNumber(dir.Name()) {
+			continue
+		}
+
+		fdDir := "/proc/" + dir.Name() + "/fd"
+		fds, _ := os.ReadDir(fdDir)
+
+		for _, fd := range fds {
+			link, _ := os.Readlink(fdDir + "/" + fd.Name())
+
+			if !links[link] {
+				continue
+			}
+
+			p := readProcessInfo(dir.Name())
+
+			if p != nil {
+				result = append(result, p)
+			}
+
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// formatSocketAddress returns socket address as a string
+func formatSocketAddress(addr *socketAddress) string {
+	switch {
+	case addr.Path != "":
+		return addr.Path
+	case addr.IP == nil:
+		return "*:" + strconv.Itoa(addr.Port)
+	}
+
+	return net.JoinHostPort(addr.IP.String(), strconv.Itoa(addr.Port))
+}
@@ -90,7 +90,7 @@ func WaitPID(action *recipe.Action) error {
 	timeout = mathutil.Between(timeout, 0.01, 3600.0)
 	timeoutDur := timeutil.SecondsToDuration(timeout)
 
-	for range time.NewTicker(25 * time.Millisecond).C {
+	for sleep(action, 25*time.Millisecond) {
 		if time.Since(start) >= timeoutDur {
 			break
 		}
@@ -107,11 +107,18 @@ func WaitPID(action *recipe.Action) error {
 				return nil
 			}
 		case action.Negative && !fsutil.IsExist(pidFile):
-			time.Sleep(time.Second)
+			if !sleep(action, time.Second) {
+				return ErrAborted
+			}
+
 			return nil
 		}
 	}
 
+	if isAborted(action) {
+		return ErrAborted
+	}
+
 	switch action.Negative {
 	case false:
 		return fmt.Errorf(
@@ -128,52 +135,40 @@ func WaitPID(action *recipe.Action) error {
 
 // WaitFS is action processor for "wait-fs"
 func WaitFS(action *recipe.Action) error {
-	var timeout float64
-
 	file, err := action.GetS(0)
 
 	if err != nil {
 		return err
 	}
 
-	if action.Has(1) {
-		timeout, err = action.GetF(1)
+	timeout, err := getWaitTimeout(action, 1)
 
-		if err != nil {
-			return err
-		}
-	} else {
-		timeout = 60.0
+	if err != nil {
+		return err
 	}
 
-	start := time.Now()
-	timeout = mathutil.Between(timeout, 0.01, 3600.0)
-	timeoutDur := timeutil.SecondsToDuration(timeout)
+	ok, err := waitFSCondition(action, file, timeout, func() (bool, error) {
+		return fsutil.IsExist(file) != action.Negative, nil
+	})
 
-	for range time.NewTicker(25 * time.Millisecond).C {
-		switch {
-		case !action.Negative && fsutil.IsExist(file),
-			action.Negative && !fsutil.IsExist(file):
-			return nil
-		}
-
-		if time.Since(start) >= timeoutDur {
-			break
-		}
+	if err != nil {
+		return err
 	}
 
-	switch action.Negative {
-	case false:
+	switch {
+	case !ok && !action.Negative:
 		return fmt.Errorf(
 			"Timeout (%g sec) reached, and %s didn't appear",
 			timeout, file,
 		)
-	default:
+	case !ok && action.Negative:
 		return fmt.Errorf(
 			"Timeout (%g sec) reached, and %s still exists",
 			timeout, file,
 		)
 	}
+
+	return nil
 }
 
 // WaitConnect is action processor for "wait-connect"
@@ -206,7 +201,7 @@ func WaitConnect(action *recipe.Action) error {
 	timeout = mathutil.Between(timeout, 0.01, 3600.0)
 	timeoutDur := timeutil.SecondsToDuration(timeout)
 
-	for range time.NewTicker(25 * time.Millisecond).C {
+	for sleep(action, 25*time.Millisecond) {
 		conn, err := net.DialTimeout(network, address, time.Second)
 
 		if conn != nil {
@@ -224,6 +219,10 @@ func WaitConnect(action *recipe.Action) error {
 		}
 	}
 
+	if isAborted(action) {
+		return ErrAborted
+	}
+
 	switch action.Negative {
 	case false:
 		return fmt.Errorf(
@@ -421,11 +420,11 @@ func sendSignalToCmd(sig syscall.Signal, cmd *exec.Cmd) error {
 		return fmt.Errorf("Can't find process PID (process already dead?)")
 	}
 
-	if cmd.ProcessState.Exited() {
+	if cmd.ProcessState != nil {
 		return fmt.Errorf("Can't send signal - process already dead")
 	}
 
-	return signal.Send(cmd.ProcessState.Pid(), sig)
+	return signal.Send(cmd.Process.Pid, sig)
 }
 
 // sendSignalToPID sends signal to PID from PID file
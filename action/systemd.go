@@ -0,0 +1,443 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/essentialkaos/ek/v13/fsutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// unitDirs is a list of directories with systemd unit files ordered by priority
+var unitDirs = []string{
+	"/etc/systemd/system",
+	"/run/systemd/system",
+	"/usr/local/lib/systemd/system",
+	"/usr/lib/systemd/system",
+	"/lib/systemd/system",
+}
+
+// unitSections contains names of type-specific sections for all unit types
+var unitSections = map[string]string{
+	"service":   "Service",
+	"socket":    "Socket",
+	"device":    "Unit",
+	"mount":     "Mount",
+	"automount": "Automount",
+	"swap":      "Swap",
+	"target":    "Unit",
+	"path":      "Path",
+	"timer":     "Timer",
+	"slice":     "Slice",
+	"scope":     "Scope",
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// ServiceProperty is action processor for "service-property"
+func ServiceProperty(action *recipe.Action) error {
+	unit, property, value, err := getUnitPropertyArgs(action)
+
+	if err != nil {
+		return err
+	}
+
+	values, err := readUnitProperty(unit, property)
+
+	if err != nil {
+		return err
+	}
+
+	var unitValue string
+
+	if len(values) != 0 {
+		unitValue = values[len(values)-1]
+	}
+
+	isEqual := isUnitValueEqual(unitValue, value)
+
+	switch {
+	case !action.Negative && len(values) == 0:
+		return fmt.Errorf("Property %s is not set for unit %s", property, unit)
+	case !action.Negative && !isEqual:
+		return fmt.Errorf(
+			"Property %s of unit %s has different value (%s ≠ %s)",
+			property, unit, fmtValue(unitValue), fmtValue(value),
+		)
+	case action.Negative && len(values) != 0 && isEqual:
+		return fmt.Errorf(
+			"Property %s of unit %s has invalid value (%s)",
+			property, unit, fmtValue(value),
+		)
+	}
+
+	return nil
+}
+
+// ServicePropertyContains is action processor for "service-property-contains"
+func ServicePropertyContains(action *recipe.Action) error {
+	unit, property, value, err := getUnitPropertyArgs(action)
+
+	if err != nil {
+		return err
+	}
+
+	values, err := readUnitProperty(unit, property)
+
+	if err != nil {
+		return err
+	}
+
+	var hasValue bool
+
+	for _, v := range values {
+		if isUnitValueEqual(v, value) || slices.Contains(splitUnitValue(v), value) {
+			hasValue = true
+			break
+		}
+	}
+
+	switch {
+	case !action.Negative && !hasValue:
+		return fmt.Errorf(
+			"Property %s of unit %s doesn't contain %s",
+			property, unit, fmtValue(value),
+		)
+	case action.Negative && hasValue:
+		return fmt.Errorf(
+			"Property %s of unit %s contains %s",
+			property, unit, fmtValue(value),
+		)
+	}
+
+	return nil
+}
+
+// JournalContains is action processor for "journal-contains"
+func JournalContains(action *recipe.Action) error {
+	unit, substr, err := getJournalArgs(action)
+
+	if err != nil {
+		return err
+	}
+
+	journal, err := readUnitJournal(action, unit)
+
+	if err != nil {
+		return err
+	}
+
+	hasSubstr := bytes.Contains(journal, []byte(substr))
+
+	switch {
+	case !action.Negative && !hasSubstr:
+		return fmt.Errorf("Journal of unit %s doesn't contain %q", unit, substr)
+	case action.Negative && hasSubstr:
+		return fmt.Errorf("Journal of unit %s contains %q", unit, substr)
+	}
+
+	return nil
+}
+
+// JournalMatch is action processor for "journal-match"
+func JournalMatch(action *recipe.Action) error {
+	unit, pattern, err := getJournalArgs(action)
+
+	if err != nil {
+		return err
+	}
+
+	rg, err := regexp.Compile(pattern)
+
+	if err != nil {
+		return err
+	}
+
+	journal, err := readUnitJournal(action, unit)
+
+	if err != nil {
+		return err
+	}
+
+	isMatch := rg.Match(journal)
+
+	switch {
+	case !action.Negative && !isMatch:
+		return fmt.Errorf("Journal of unit %s doesn't match regexp %q", unit, pattern)
+	case action.Negative && isMatch:
+		return fmt.Errorf("Journal of unit %s matches regexp %q", unit, pattern)
+	}
+
+	return nil
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getUnitPropertyArgs returns unit name, property name and value from action
+// arguments
+func getUnitPropertyArgs(action *recipe.Action) (string, string, string, error) {
+	unit, err := action.GetS(0)
+
+	if err != nil {
+		return "", "", "", err
+	}
+
+	property, err := action.GetS(1)
+
+	if err != nil {
+		return "", "", "", err
+	}
+
+	value, err := action.GetS(2)
+
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return getUnitName(unit), property, value, nil
+}
+
+// getJournalArgs returns unit name and pattern from action arguments
+func getJournalArgs(action *recipe.Action) (string, string, error) {
+	unit, err := action.GetS(0)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	pattern, err := action.GetS(1)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return getUnitName(unit), pattern, nil
+}
+
+// getUnitName returns unit name with type suffix
+func getUnitName(unit string) string {
+	if getUnitType(unit) == "" {
+		return unit + ".service"
+	}
+
+	return unit
+}
+
+// getUnitType returns type of unit from unit name suffix
+func getUnitType(unit string) string {
+	unitType := strings.TrimPrefix(filepath.Ext(unit), ".")
+
+	if unitSections[unitType] == "" {
+		return ""
+	}
+
+	return unitType
+}
+
+// parseUnitPropertyName parses property name in "Section.Key" format. If section
+// is not defined, type-specific section of unit is used.
+func parseUnitPropertyName(unit, property string) (string, string) {
+	section, key, ok := strings.Cut(property, ".")
+
+	if !ok {
+		return unitSections[getUnitType(unit)], property
+	}
+
+	return section, key
+}
+
+// readUnitProperty reads all effective values of unit property from unit file
+// and drop-in files
+func readUnitProperty(unit, property string) ([]string, error) {
+	files := findUnitFiles(unit)
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("Can't find unit file for unit %s", unit)
+	}
+
+	var values []string
+
+	section, key := parseUnitPropertyName(unit, property)
+
+	for _, file := range files {
+		fileValues, err := readUnitFileProperty(file, section, key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range fileValues {
+			// Empty value resets all previous assignments
+			if v == "" {
+				values = nil
+			} else {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// findUnitFiles returns path to unit file and all drop-in files in the order
+// they are applied
+func findUnitFiles(unit string) []string {
+	var unitFile string
+
+	names := []string{unit}
+
+	// Instances of template units use template unit file
+	if strings.Contains(unit, "@") {
+		prefix, _, _ := strings.Cut(unit, "@")
+		names = append(names, prefix+"@"+filepath.Ext(unit))
+	}
+
+	for _, name := range names {
+		for _, dir := range unitDirs {
+			if fsutil.IsRegular(dir+"/"+name) || fsutil.IsLink(dir+"/"+name) {
+				unitFile = dir + "/" + name
+				break
+			}
+		}
+
+		if unitFile != "" {
+			break
+		}
+	}
+
+	if unitFile == "" {
+		return nil
+	}
+
+	// Drop-ins with the same name from directories with higher priority
+	// override drop-ins from directories with lower priority
+	dropIns := make(map[string]string)
+
+	for _, name := range slices.Backward(names) {
+		for _, dir := range slices.Backward(unitDirs) {
+			files, _ := filepath.Glob(dir + "/" + name + ".d/*.conf")
+
+			for _, file := range files {
+				dropIns[filepath.Base(file)] = file
+			}
+		}
+	}
+
+	result := []string{unitFile}
+
+	for _, name := range slices.Sorted(maps.Keys(dropIns)) {
+		result = append(result, dropIns[name])
+	}
+
+	return result
+}
+
+// readUnitFileProperty reads all values of property from given section of unit file
+func readUnitFileProperty(file, section, key string) ([]string, error) {
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read unit file %s: %v", file, err)
+	}
+
+	var values []string
+	var curSection string
+
+	data = bytes.ReplaceAll(data, []byte("\\\n"), []byte(" "))
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "",
+			strings.HasPrefix(line, "#"),
+			strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "["):
+			curSection = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			continue
+		case curSection != section:
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+
+		if ok && strings.TrimSpace(name) == key {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+
+	return values, nil
+}
+
+// isUnitValueEqual returns true if values are equal. Boolean values are compared
+// as booleans.
+func isUnitValueEqual(v1, v2 string) bool {
+	b1, ok1 := parseUnitBool(v1)
+	b2, ok2 := parseUnitBool(v2)
+
+	if ok1 && ok2 {
+		return b1 == b2
+	}
+
+	return v1 == v2
+}
+
+// parseUnitBool parses systemd boolean value
+func parseUnitBool(v string) (bool, bool) {
+	switch strings.ToLower(v) {
+	case "1", "yes", "y", "true", "t", "on":
+		return true, true
+	case "0", "no", "n", "false", "f", "off":
+		return false, true
+	}
+
+	return false, false
+}
+
+// splitUnitValue splits value with list of items
+func splitUnitValue(v string) []string {
+	var result []string
+
+	for _, item := range strings.Fields(v) {
+		result = append(result, strings.Trim(item, `"'`))
+	}
+
+	return result
+}
+
+// readUnitJournal returns messages from journal of unit since command start
+func readUnitJournal(action *recipe.Action, unit string) ([]byte, error) {
+	since := "@" + strconv.FormatInt(action.Command.Started.Unix(), 10)
+
+	cmd := exec.Command(
+		"journalctl", "--unit", unit, "--since", since,
+		"--output", "cat", "--no-pager", "--quiet",
+	)
+
+	output, err := cmd.Output()
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read journal of unit %s: %v", unit, err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,508 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/fsutil"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// ChangeTracker records original state of filesystem objects before modification
+// and restores it on demand
+type ChangeTracker struct {
+	dir        string
+	changes    []*fsChange
+	isRestored bool
+	mx         sync.Mutex
+}
+
+// objectID contains device and inode number of filesystem object
+type objectID struct {
+	dev uint64
+	ino uint64
+}
+
+// treeFlags contains inode flags of all objects in directory tree
+// (relative path → flags)
+type treeFlags map[string]int32
+
+// fsChange contains info about original state of filesystem object
+type fsChange struct {
+	path    string      // Path to object
+	backup  string      // Path to object copy
+	xattrs  treeXattrs  // Original extended attributes
+	flags   treeFlags   // Original inode flags
+	mode    os.FileMode // Original mode
+	uid     int         // Original owner UID
+	gid     int         // Original owner GID
+	isExist bool        // Object existed before modification
+	isMeta  bool        // Only mode, owner and attributes were saved
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// lockFlags contains inode flags which forbid modification of object
+const lockFlags = 0x00000010 | 0x00000020 // immutable | append-only
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// NewChangeTracker creates new change tracker which uses given directory for
+// storing copies of original objects
+func NewChangeTracker(dir string) *ChangeTracker {
+	return &ChangeTracker{dir: dir}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Track saves original state of object. If withContent is false, only mode,
+// owner, extended attributes and inode flags of the object will be saved.
+func (t *ChangeTracker) Track(path string, withContent bool) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	path, err := filepath.Abs(path)
+
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(path)
+
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Can't save original state of %s: %v", path, err)
+	}
+
+	if info == nil {
+		path, err = getTopMissingDir(path), nil
+	}
+
+	if t.isTracked(path, withContent) {
+		return nil
+	}
+
+	change := &fsChange{path: path}
+
+	if info != nil {
+		change.isExist = true
+		change.isMeta = !withContent
+		change.mode = info.Mode()
+		change.uid, change.gid = getObjectOwner(info)
+	}
+
+	switch {
+	case change.isExist && withContent:
+		change.xattrs, err = getTreeXattrs(path)
+		change.flags = getTreeFlags(path, true)
+	case change.isExist && info.Mode()&os.ModeSymlink == 0:
+		change.xattrs = treeXattrs{}
+		change.xattrs["."], err = getXattrs(path)
+		change.flags = getTreeFlags(path, false)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Can't save original state of %s: %v", path, err)
+	}
+
+	if change.isExist && withContent {
+		change.backup = t.dir + "/" + strconv.Itoa(len(t.changes))
+
+		err = copyObject(path, change.backup)
+
+		if err != nil {
+			return fmt.Errorf("Can't save original state of %s: %v", path, err)
+		}
+	}
+
+	t.changes = append(t.changes, change)
+
+	return nil
+}
+
+// Restore restores original state of all tracked objects and returns report
+// with info about restored objects
+func (t *ChangeTracker) Restore() []string {
+	if t == nil {
+		return nil
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if t.isRestored {
+		return nil
+	}
+
+	var report []string
+
+	for i := len(t.changes) - 1; i >= 0; i-- {
+		change := t.changes[i]
+		info, err := change.restore()
+
+		switch {
+		case err != nil:
+			report = append(report, fmt.Sprintf("Can't restore %s: %v", change.path, err))
+		case info != "":
+			report = append(report, info)
+		}
+	}
+
+	t.isRestored = true
+
+	return report
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// isTracked returns true if original state of object is already saved
+func (t *ChangeTracker) isTracked(path string, withContent bool) bool {
+	for _, change := range t.changes {
+		switch {
+		case change.path == path && (!withContent || !change.isMeta),
+			!change.isMeta && strings.HasPrefix(path, change.path+"/"):
+			return true
+		}
+	}
+
+	return false
+}
+
+// restore restores original state of object
+func (c *fsChange) restore() (string, error) {
+	info, err := os.Lstat(c.path)
+
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	switch {
+	case !c.isExist:
+		if info == nil {
+			return "", nil
+		}
+
+		err = os.RemoveAll(c.path)
+
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Removed %s (created during tests)", c.path), nil
+
+	case c.isMeta:
+		if info == nil {
+			return "", fmt.Errorf("Object doesn't exist")
+		}
+
+		return c.restoreMeta(info)
+	}
+
+	if info != nil {
+		// Immutable objects can't be removed, so we have to unlock them first
+		err = unlockTree(c.path, true)
+
+		if err != nil {
+			return "", err
+		}
+
+		err = os.RemoveAll(c.path)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = copyObject(c.backup, c.path)
+
+	if err != nil {
+		return "", err
+	}
+
+	err = setTreeXattrs(c.path, c.xattrs)
+
+	if err != nil {
+		return "", err
+	}
+
+	err = setTreeFlags(c.path, c.flags)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Restored %s", c.path), nil
+}
+
+// restoreMeta restores mode, owner, extended attributes and inode flags of object
+func (c *fsChange) restoreMeta(info os.FileInfo) (string, error) {
+	uid, gid := getObjectOwner(info)
+	isOwnerChanged := info.Mode() != c.mode || uid != c.uid || gid != c.gid
+	isFlagsChanged := !maps.Equal(getTreeFlags(c.path, false), c.flags)
+
+	// Mode and owner of immutable object can't be changed, so we have to
+	// unlock it first
+	err := unlockTree(c.path, false)
+
+	if err != nil {
+		return "", err
+	}
+
+	if isOwnerChanged {
+		err = restoreObjectAttrs(c.path, c.mode, c.uid, c.gid)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Changing owner resets some attributes (e.g. file capabilities)
+	err = setTreeXattrs(c.path, c.xattrs)
+
+	if err != nil {
+		return "", err
+	}
+
+	err = setTreeFlags(c.path, c.flags)
+
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case isOwnerChanged:
+		return fmt.Sprintf(
+			"Restored mode and owner of %s (%s %d:%d)",
+			c.path, c.mode, c.uid, c.gid,
+		), nil
+	case isFlagsChanged:
+		return fmt.Sprintf("Restored file attributes of %s", c.path), nil
+	}
+
+	return "", nil
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// copyObject recursively copies filesystem object with mode, owner and
+// modification time. Hard links inside copied tree are preserved.
+func copyObject(from, to string) error {
+	return copyTree(from, to, map[objectID]string{})
+}
+
+// copyTree copies filesystem object and uses links map for tracking already
+// copied objects with more than one hard link
+func copyTree(from, to string, links map[objectID]string) error {
+	info, err := os.Lstat(from)
+
+	if err != nil {
+		return err
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if ok && !info.IsDir() && stat.Nlink > 1 {
+		id := objectID{uint64(stat.Dev), uint64(stat.Ino)}
+
+		if links[id] != "" {
+			return os.Link(links[id], to)
+		}
+
+		links[id] = to
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(from)
+
+		if err != nil {
+			return err
+		}
+
+		err = os.Symlink(target, to)
+
+		if err != nil {
+			return err
+		}
+
+	case info.IsDir():
+		err = os.Mkdir(to, 0700)
+
+		if err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(from)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			err = copyTree(from+"/"+entry.Name(), to+"/"+entry.Name(), links)
+
+			if err != nil {
+				return err
+			}
+		}
+
+	case info.Mode().IsRegular():
+		err = fsutil.CopyFile(from, to, 0600)
+
+		if err != nil {
+			return err
+		}
+
+	case ok && info.Mode()&(os.ModeDevice|os.ModeNamedPipe|os.ModeSocket) != 0:
+		err = syscall.Mknod(to, stat.Mode&syscall.S_IFMT|0600, int(stat.Rdev))
+
+		if err != nil {
+			return fmt.Errorf("Can't create %s: %v", to, err)
+		}
+
+	default:
+		return fmt.Errorf("Object %s has unsupported type (%s)", from, info.Mode().Type())
+	}
+
+	uid, gid := getObjectOwner(info)
+
+	err = restoreObjectAttrs(to, info.Mode(), uid, gid)
+
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		return os.Chtimes(to, time.Time{}, info.ModTime())
+	}
+
+	return nil
+}
+
+// restoreObjectAttrs sets owner and mode of object
+func restoreObjectAttrs(path string, mode os.FileMode, uid, gid int) error {
+	err := os.Lchown(path, uid, gid)
+
+	// Non-root user can't change owner of object, so we just ignore this error
+	if err != nil && os.Geteuid() == 0 {
+		return err
+	}
+
+	if mode&os.ModeSymlink != 0 {
+		return nil
+	}
+
+	return os.Chmod(path, mode&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+}
+
+// getObjectOwner returns UID and GID of object owner
+func getObjectOwner(info os.FileInfo) (int, int) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return -1, -1
+	}
+
+	return int(stat.Uid), int(stat.Gid)
+}
+
+// getTreeFlags returns inode flags of object and all objects in directory tree
+// if recursive is true. Objects which don't support inode flags are skipped.
+func getTreeFlags(root string, recursive bool) treeFlags {
+	result := make(treeFlags)
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || (path != root && !recursive) {
+			return filepath.SkipDir
+		}
+
+		if !d.Type().IsRegular() && !d.IsDir() {
+			return nil
+		}
+
+		flags, err := getFileFlags(path)
+
+		if err == nil {
+			relPath, _ := filepath.Rel(root, path)
+			result[relPath] = flags
+		}
+
+		return nil
+	})
+
+	return result
+}
+
+// setTreeFlags sets inode flags for all objects in directory tree
+func setTreeFlags(root string, flags treeFlags) error {
+	for relPath, objFlags := range flags {
+		path := filepath.Join(root, relPath)
+		curFlags, err := getFileFlags(path)
+
+		if err != nil {
+			return err
+		}
+
+		if curFlags == objFlags {
+			continue
+		}
+
+		err = setFileFlags(path, objFlags)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unlockTree removes immutable and append-only flags from object and all objects
+// in directory tree if recursive is true
+func unlockTree(root string, recursive bool) error {
+	for relPath, flags := range getTreeFlags(root, recursive) {
+		if flags&lockFlags == 0 {
+			continue
+		}
+
+		err := setFileFlags(filepath.Join(root, relPath), flags&^lockFlags)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getTopMissingDir returns path to the topmost missing directory for given path
+func getTopMissingDir(path string) string {
+	for {
+		parent := filepath.Dir(path)
+
+		if parent == path || fsutil.IsExist(parent) {
+			return path
+		}
+
+		path = parent
+	}
+}
@@ -0,0 +1,316 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/fmtutil"
+	"github.com/essentialkaos/ek/v13/timeutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// PROP_CGROUP is name of property with path to command cgroup
+const PROP_CGROUP = "CGROUP"
+
+// blockSize is size of block used in rusage I/O counters
+const blockSize = 512
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// MaxRSS is action processor for "max-rss"
+func MaxRSS(action *recipe.Action, cmd *exec.Cmd) error {
+	limit, usage, err := getUsageLimitAndUsage(action, cmd, true)
+
+	if err != nil {
+		return err
+	}
+
+	return checkUsageSize(action, "Peak memory usage", usage.MaxRSS, limit)
+}
+
+// MaxCPUTime is action processor for "max-cpu-time"
+func MaxCPUTime(action *recipe.Action, cmd *exec.Cmd) error {
+	limit, err := action.GetF(0)
+
+	if err != nil {
+		return err
+	}
+
+	usage, err := waitResourceUsage(action, cmd)
+
+	if err != nil {
+		return err
+	}
+
+	cpuTime := usage.CPUTime().Seconds()
+
+	switch {
+	case !action.Negative && cpuTime > limit:
+		return fmt.Errorf(
+			"CPU time is greater than %gs (%s)", limit,
+			timeutil.PrettyDuration(usage.CPUTime()),
+		)
+	case action.Negative && cpuTime <= limit:
+		return fmt.Errorf(
+			"CPU time is less than or equal to %gs (%s)", limit,
+			timeutil.PrettyDuration(usage.CPUTime()),
+		)
+	}
+
+	return nil
+}
+
+// MaxIORead is action processor for "max-io-read"
+func MaxIORead(action *recipe.Action, cmd *exec.Cmd) error {
+	limit, usage, err := getUsageLimitAndUsage(action, cmd, false)
+
+	if err != nil {
+		return err
+	}
+
+	return checkUsageSize(action, "Amount of read data", usage.IORead, limit)
+}
+
+// MaxIOWrite is action processor for "max-io-write"
+func MaxIOWrite(action *recipe.Action, cmd *exec.Cmd) error {
+	limit, usage, err := getUsageLimitAndUsage(action, cmd, false)
+
+	if err != nil {
+		return err
+	}
+
+	return checkUsageSize(action, "Amount of written data", usage.IOWrite, limit)
+}
+
+// MaxDuration is action processor for "max-duration"
+func MaxDuration(action *recipe.Action, cmd *exec.Cmd) error {
+	maxDur, err := action.GetF(0)
+
+	if err != nil {
+		return err
+	}
+
+	deadline := action.Command.Started.Add(timeutil.SecondsToDuration(maxDur))
+
+	for sleep(action, 25*time.Millisecond) {
+		if cmd.ProcessState != nil || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	if isAborted(action) {
+		return ErrAborted
+	}
+
+	isFinished := cmd.ProcessState != nil
+
+	switch {
+	case !action.Negative && !isFinished:
+		return fmt.Errorf("The process is still working after %gs", maxDur)
+	case action.Negative && isFinished:
+		return fmt.Errorf(
+			"The process has finished in less than %gs (%s)", maxDur,
+			timeutil.PrettyDuration(time.Since(action.Command.Started)),
+		)
+	}
+
+	return nil
+}
+
+// GetResourceUsage returns info about resources used by finished process. If
+// command was executed in cgroup, stats from cgroup are used instead of rusage.
+func GetResourceUsage(c *recipe.Command, cmd *exec.Cmd) *recipe.ResourceUsage {
+	if cmd == nil || cmd.ProcessState == nil {
+		return nil
+	}
+
+	rusage, ok := cmd.ProcessState.SysUsage().(*syscall.Rusage)
+
+	if !ok || rusage == nil {
+		return nil
+	}
+
+	usage := &recipe.ResourceUsage{
+		MaxRSS:     uint64(rusage.Maxrss) * 1024, // ru_maxrss is in kilobytes
+		UserTime:   time.Duration(rusage.Utime.Nano()),
+		SystemTime: time.Duration(rusage.Stime.Nano()),
+		IORead:     uint64(rusage.Inblock) * blockSize,
+		IOWrite:    uint64(rusage.Oublock) * blockSize,
+	}
+
+	if c != nil && c.Data.Has(PROP_CGROUP) {
+		readCgroupUsage(c.Data.Get(PROP_CGROUP).(string), usage)
+	}
+
+	return usage
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getUsageLimitAndUsage parses size limit from action and returns it with info
+// about resources used by process
+func getUsageLimitAndUsage(action *recipe.Action, cmd *exec.Cmd, nonZero bool) (uint64, *recipe.ResourceUsage, error) {
+	limitStr, err := action.GetS(0)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	limit := fmtutil.ParseSize(limitStr)
+
+	if limit == 0 && (nonZero || limitStr != "0") {
+		return 0, nil, fmt.Errorf("Can't parse size %q", limitStr)
+	}
+
+	usage, err := waitResourceUsage(action, cmd)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return limit, usage, nil
+}
+
+// checkUsageSize checks used amount of resource against limit
+func checkUsageSize(action *recipe.Action, name string, value, limit uint64) error {
+	switch {
+	case !action.Negative && value > limit:
+		return fmt.Errorf(
+			"%s is greater than %s (%s)", name,
+			fmtutil.PrettySize(limit), fmtutil.PrettySize(value),
+		)
+	case action.Negative && value <= limit:
+		return fmt.Errorf(
+			"%s is less than or equal to %s (%s)", name,
+			fmtutil.PrettySize(limit), fmtutil.PrettySize(value),
+		)
+	}
+
+	return nil
+}
+
+// waitResourceUsage waits until process is finished and returns info about
+// used resources
+func waitResourceUsage(action *recipe.Action, cmd *exec.Cmd) (*recipe.ResourceUsage, error) {
+	var err error
+
+	timeout := 60.0
+
+	if action.Has(1) {
+		timeout, err = action.GetF(1)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+
+	for sleep(action, 25*time.Millisecond) {
+		if cmd.ProcessState != nil {
+			break
+		}
+
+		if time.Since(start) > timeutil.SecondsToDuration(timeout) {
+			return nil, fmt.Errorf("Reached timeout (%g sec)", timeout)
+		}
+	}
+
+	if cmd.ProcessState == nil {
+		return nil, ErrAborted
+	}
+
+	usage := GetResourceUsage(action.Command, cmd)
+
+	if usage == nil {
+		return nil, fmt.Errorf("Can't get resource usage from process state")
+	}
+
+	return usage, nil
+}
+
+// readCgroupUsage reads info about used resources from cgroup stats. Cgroup stats
+// include all processes created by command, so they replace values from rusage.
+// Values which are not available (e.g. controller is disabled) are kept as is.
+func readCgroupUsage(cgroupDir string, usage *recipe.ResourceUsage) {
+	peak, err := readCgroupValue(cgroupDir + "/memory.peak")
+
+	if err == nil {
+		usage.MaxRSS = peak
+	}
+
+	cpuStat, err := readCgroupStat(cgroupDir + "/cpu.stat")
+
+	if err == nil {
+		usage.UserTime = time.Duration(cpuStat["user_usec"]) * time.Microsecond
+		usage.SystemTime = time.Duration(cpuStat["system_usec"]) * time.Microsecond
+	}
+
+	ioStat, err := readCgroupStat(cgroupDir + "/io.stat")
+
+	if err == nil {
+		usage.IORead, usage.IOWrite = ioStat["rbytes"], ioStat["wbytes"]
+	}
+}
+
+// readCgroupValue reads single numeric value from cgroup interface file
+func readCgroupValue(file string) (uint64, error) {
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// readCgroupStat reads flat keyed (cpu.stat) or nested keyed (io.stat) cgroup
+// interface file. Values with the same key from different lines (e.g. for
+// different devices in io.stat) are summed.
+func readCgroupStat(file string) (map[string]uint64, error) {
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]uint64{}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 2 && !strings.Contains(fields[0], "="):
+			value, _ := strconv.ParseUint(fields[1], 10, 64)
+			result[fields[0]] += value
+
+		case len(fields) > 1:
+			// Nested keyed line starts with device number
+			for _, field := range fields[1:] {
+				key, value, ok := strings.Cut(field, "=")
+
+				if ok {
+					v, _ := strconv.ParseUint(value, 10, 64)
+					result[key] += v
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,172 @@
+package action
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// xattrs contains extended attributes of object (name → value)
+type xattrs map[string][]byte
+
+// treeXattrs contains extended attributes of all objects in directory tree
+// (relative path → attributes)
+type treeXattrs map[string]xattrs
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getXattrs returns all extended attributes of object
+func getXattrs(path string) (xattrs, error) {
+	names, err := listXattrs(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(xattrs)
+
+	for _, name := range names {
+		value, err := getXattr(path, name)
+
+		if err != nil {
+			return nil, fmt.Errorf("Can't read attribute %s of %s: %v", name, path, err)
+		}
+
+		result[name] = value
+	}
+
+	return result, nil
+}
+
+// setXattrs sets extended attributes of object and removes all other attributes
+func setXattrs(path string, attrs xattrs) error {
+	names, err := listXattrs(path)
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		_, ok := attrs[name]
+
+		// SELinux labels are managed by the system, so we can't remove them. Other
+		// security attributes (e.g. file capabilities) are removed as usual.
+		if ok || name == xattrSELinux {
+			continue
+		}
+
+		err = syscall.Removexattr(path, name)
+
+		if err != nil {
+			return fmt.Errorf("Can't remove attribute %s of %s: %v", name, path, err)
+		}
+	}
+
+	for name, value := range attrs {
+		err = syscall.Setxattr(path, name, value, 0)
+
+		if err != nil {
+			return fmt.Errorf("Can't set attribute %s of %s: %v", name, path, err)
+		}
+	}
+
+	return nil
+}
+
+// getXattr returns value of extended attribute
+func getXattr(path, name string) ([]byte, error) {
+	size, err := syscall.Getxattr(path, name, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	size, err = syscall.Getxattr(path, name, buf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf[:size], nil
+}
+
+// listXattrs returns names of all extended attributes of object
+func listXattrs(path string) ([]string, error) {
+	size, err := syscall.Listxattr(path, nil)
+
+	if errors.Is(err, syscall.ENOTSUP) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read attributes of %s: %v", path, err)
+	}
+
+	if size == 0 {
+		return nil, nil
+	}
+
+	buf := make([]byte, size)
+	size, err = syscall.Listxattr(path, buf)
+
+	if err != nil {
+		return nil, fmt.Errorf("Can't read attributes of %s: %v", path, err)
+	}
+
+	return strings.Split(strings.TrimRight(string(buf[:size]), "\x00"), "\x00"), nil
+}
+
+// getTreeXattrs returns extended attributes of all objects in directory tree.
+// Symbolic links are ignored.
+func getTreeXattrs(root string) (treeXattrs, error) {
+	result := make(treeXattrs)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
+		attrs, err := getXattrs(path)
+
+		if err != nil {
+			return err
+		}
+
+		relPath, _ := filepath.Rel(root, path)
+		result[relPath] = attrs
+
+		return nil
+	})
+
+	return result, err
+}
+
+// setTreeXattrs sets extended attributes for all objects in directory tree
+func setTreeXattrs(root string, attrs treeXattrs) error {
+	for relPath, objAttrs := range attrs {
+		err := setXattrs(filepath.Join(root, relPath), objAttrs)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -69,6 +70,16 @@ const (
 	OPT_QUIET              = "q:quiet"
 	OPT_IGNORE_PACKAGES    = "ip:ignore-packages"
 	OPT_NO_CLEANUP         = "nl:no-cleanup"
+	OPT_ROLLBACK           = "R:rollback"
+	OPT_CORE_DUMPS         = "cd:core-dumps"
+	OPT_WRAPPER            = "W:wrapper"
+	OPT_COVERAGE           = "cv:coverage"
+	OPT_TIMEOUT            = "to:timeout"
+	OPT_TEARDOWN_TIMEOUT   = "tt:teardown-timeout"
+	OPT_BENCHMARK          = "bm:benchmark"
+	OPT_BASELINE           = "bl:baseline"
+	OPT_BASELINE_THRESHOLD = "bt:baseline-threshold"
+	OPT_BASELINE_UPDATE    = "bu:baseline-update"
 	OPT_NO_COLOR           = "nc:no-color"
 	OPT_HELP               = "h:help"
 	OPT_VER                = "v:version"
@@ -90,7 +101,7 @@ var optMap = options.Map{
 	OPT_EXTRA:              {Type: options.INT, Value: 10, Min: 1, Max: 256},
 	OPT_TIME:               {Type: options.BOOL},
 	OPT_PAUSE:              {Type: options.FLOAT, Max: 60},
-	OPT_FORMAT:             {},
+	OPT_FORMAT:             {Mergeble: true},
 	OPT_DIR:                {},
 	OPT_PATH:               {},
 	OPT_ERROR_DIR:          {},
@@ -98,6 +109,16 @@ var optMap = options.Map{
 	OPT_QUIET:              {Type: options.BOOL},
 	OPT_IGNORE_PACKAGES:    {Type: options.BOOL},
 	OPT_NO_CLEANUP:         {Type: options.BOOL},
+	OPT_ROLLBACK:           {Type: options.BOOL},
+	OPT_CORE_DUMPS:         {Type: options.BOOL},
+	OPT_WRAPPER:            {},
+	OPT_COVERAGE:           {},
+	OPT_TIMEOUT:            {Type: options.FLOAT, Min: 1, Max: 86400},
+	OPT_TEARDOWN_TIMEOUT:   {Type: options.FLOAT, Value: 30.0, Min: 1, Max: 3600},
+	OPT_BENCHMARK:          {Type: options.BOOL},
+	OPT_BASELINE:           {},
+	OPT_BASELINE_THRESHOLD: {Type: options.FLOAT, Value: 10.0, Min: 0.1, Max: 1000},
+	OPT_BASELINE_UPDATE:    {Type: options.BOOL},
 	OPT_NO_COLOR:           {Type: options.BOOL},
 	OPT_HELP:               {Type: options.BOOL},
 	OPT_VER:                {Type: options.MIXED},
@@ -108,9 +129,24 @@ var optMap = options.Map{
 	OPT_GENERATE_MAN: {Type: options.BOOL},
 }
 
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// outputFile is renderer output file
+type outputFile struct {
+	*os.File
+
+	format string
+	err    error
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
 var colorTagApp, colorTagVer string
 var rawOutput bool
 
+// outputFiles is a slice with renderers output files
+var outputFiles []*outputFile
+
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 func Run(gitRev string, gomod []byte) {
@@ -230,6 +266,18 @@ func validateOptions() {
 		}
 	}
 
+	if options.GetB(OPT_CORE_DUMPS) && errsDir == "" {
+		printErrorAndExit("Option --core-dumps can be used only with --error-dir")
+	}
+
+	if options.Has(OPT_WRAPPER) {
+		_, err := parser.ParseWrapper(options.GetS(OPT_WRAPPER))
+
+		if err != nil {
+			printErrorAndExit(err.Error())
+		}
+	}
+
 	wrkDir := options.GetS(OPT_DIR)
 
 	if wrkDir != "" {
@@ -257,6 +305,10 @@ func process(file string) {
 		r.Dir, _ = filepath.Abs(filepath.Dir(file))
 	}
 
+	if options.Has(OPT_WRAPPER) {
+		r.Wrapper, _ = parser.ParseWrapper(options.GetS(OPT_WRAPPER))
+	}
+
 	switch {
 	case options.GetB(OPT_LIST_PACKAGES),
 		options.GetB(OPT_LIST_PACKAGES_FLAT):
@@ -275,11 +327,27 @@ func process(file string) {
 	}
 
 	cfg := &executor.Config{
-		Quiet:          options.GetB(OPT_QUIET),
-		DisableCleanup: options.GetB(OPT_NO_CLEANUP),
-		DebugLines:     options.GetI(OPT_EXTRA),
-		Pause:          options.GetF(OPT_PAUSE),
-		ErrsDir:        errDir,
+		Quiet:           options.GetB(OPT_QUIET),
+		DisableCleanup:  options.GetB(OPT_NO_CLEANUP),
+		Rollback:        options.GetB(OPT_ROLLBACK),
+		CoreDumps:       options.GetB(OPT_CORE_DUMPS),
+		Timeout:         options.GetF(OPT_TIMEOUT),
+		TeardownTimeout: options.GetF(OPT_TEARDOWN_TIMEOUT),
+		DebugLines:      options.GetI(OPT_EXTRA),
+		Pause:           options.GetF(OPT_PAUSE),
+		ErrsDir:         errDir,
+
+		Benchmark:         options.GetB(OPT_BENCHMARK),
+		BaselineThreshold: options.GetF(OPT_BASELINE_THRESHOLD),
+		BaselineUpdate:    options.GetB(OPT_BASELINE_UPDATE),
+	}
+
+	if options.Has(OPT_BASELINE) {
+		cfg.BaselineFile, _ = filepath.Abs(options.GetS(OPT_BASELINE))
+	}
+
+	if options.Has(OPT_COVERAGE) {
+		cfg.CoverageDir, _ = filepath.Abs(options.GetS(OPT_COVERAGE))
 	}
 
 	e := executor.NewExecutor(cfg)
@@ -288,8 +356,9 @@ func process(file string) {
 	validate(e, r, tags)
 
 	rr := getRenderer()
+	ok := e.Run(rr, r, tags)
 
-	if !e.Run(rr, r, tags) {
+	if !closeOutputFiles() || !ok {
 		os.Exit(1)
 	}
 }
@@ -372,30 +441,138 @@ func getValidationConfig(tags []string) *executor.ValidationConfig {
 
 // getRenderer returns renderer for executor
 func getRenderer() render.Renderer {
-	if options.GetB(OPT_QUIET) {
-		return &render.QuietRenderer{}
+	var stdoutRenderer render.Renderer
+	var fileRenderers []render.Renderer
+
+	// Values of format option are merged and split by spaces, commas and
+	// semicolons, so paths to output files with these symbols must be quoted
+	targets := strutil.Fields(options.GetS(OPT_FORMAT))
+
+	// Check formats before creating output files
+	for _, target := range targets {
+		format, _, hasFile := strings.Cut(target, ":")
+
+		if newRenderer(format, nil) != nil {
+			continue
+		}
+
+		if !hasFile && len(targets) > 1 {
+			printErrorAndExit(
+				"Unknown output format %s (paths to output files with spaces, commas or semicolons must be quoted)",
+				format,
+			)
+		}
+
+		printErrorAndExit("Unknown output format %s", format)
+	}
+
+	for _, target := range targets {
+		format, file, hasFile := strings.Cut(target, ":")
+
+		if !hasFile {
+			if stdoutRenderer != nil {
+				printErrorAndExit("Only one output format can be used without path to output file")
+			}
+
+			stdoutRenderer = newRenderer(format, nil)
+			continue
+		}
+
+		if file == "" {
+			printErrorAndExit("Path to output file for format %s is empty", format)
+		}
+
+		fd, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+
+		if err != nil {
+			printErrorAndExit("Can't create output file for format %s: %v", format, err)
+		}
+
+		output := &outputFile{File: fd, format: format}
+		outputFiles = append(outputFiles, output)
+		fileRenderers = append(fileRenderers, newRenderer(format, output))
+	}
+
+	switch {
+	case options.GetB(OPT_QUIET):
+		stdoutRenderer = &render.QuietRenderer{}
+	case stdoutRenderer == nil:
+		stdoutRenderer = &render.TerminalRenderer{PrintExecTime: options.GetB(OPT_TIME)}
+	}
+
+	if len(fileRenderers) == 0 {
+		return stdoutRenderer
 	}
 
-	if !options.Has(OPT_FORMAT) {
-		return &render.TerminalRenderer{PrintExecTime: options.GetB(OPT_TIME)}
+	return &render.MultiRenderer{
+		Renderers: append([]render.Renderer{stdoutRenderer}, fileRenderers...),
 	}
+}
 
-	switch strings.ToLower(options.GetS(OPT_FORMAT)) {
+// newRenderer creates renderer for given format which writes data to given writer.
+// It returns nil if format is unknown.
+func newRenderer(format string, w io.Writer) render.Renderer {
+	switch strings.ToLower(format) {
 	case "json":
-		return &render.JSONRenderer{}
+		return &render.JSONRenderer{Writer: w}
 	case "xml":
-		return &render.XMLRenderer{Version: VER}
+		return &render.XMLRenderer{Version: VER, Writer: w}
 	case "tap13":
-		return &render.TAP13Renderer{Version: VER}
+		return &render.TAP13Renderer{Version: VER, Writer: w}
 	case "tap14":
-		return &render.TAP14Renderer{Version: VER}
+		return &render.TAP14Renderer{Version: VER, Writer: w}
+	case "junit":
+		return &render.JUnitRenderer{Version: VER, Writer: w}
+	case "html":
+		return &render.HTMLRenderer{Version: VER, Writer: w}
+	case "ndjson":
+		return &render.NDJSONRenderer{Writer: w}
 	}
 
-	printErrorAndExit("Unknown output format %s", options.GetS(OPT_FORMAT))
-
 	return nil
 }
 
+// closeOutputFiles closes all renderers output files and prints errors about
+// failed writes
+func closeOutputFiles() bool {
+	ok := true
+
+	for _, output := range outputFiles {
+		err := output.Close()
+
+		if output.err != nil {
+			err = output.err
+		}
+
+		if err != nil {
+			terminal.Error("Can't save %s output to %s: %v", output.format, output.Name(), err)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Write writes data to output file and saves the first write error
+func (o *outputFile) Write(data []byte) (int, error) {
+	// Don't write anything after error, because output is already broken
+	if o.err != nil {
+		return 0, o.err
+	}
+
+	n, err := o.File.Write(data)
+
+	if err != nil {
+		o.err = err
+	}
+
+	return n, err
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
 // printErrorAndExit print error message and exit with exit code 1
 func printErrorAndExit(f string, a ...interface{}) {
 	terminal.Error(f, a...)
@@ -441,7 +618,7 @@ func genUsage() *usage.Info {
 	info.AddOption(OPT_VARIABLES, "List recipe variables")
 	info.AddOption(OPT_BARCODE, "Show unique barcode for test {s-}(based on recipe and required packages){!}")
 	info.AddOption(OPT_TIME, "Print execution time for every action")
-	info.AddOption(OPT_FORMAT, "Output format {s-}(tap13|tap14|json|xml){!}", "format")
+	info.AddOption(OPT_FORMAT, "One or more output formats with optional path to output file {s-}(tap13|tap14|json|xml|junit|html|ndjson){!}", "format")
 	info.AddOption(OPT_DIR, "Path to working directory", "dir")
 	info.AddOption(OPT_PATH, "Path to directory with binaries", "path")
 	info.AddOption(OPT_ERROR_DIR, "Path to directory for errors data", "dir")
@@ -449,6 +626,16 @@ func genUsage() *usage.Info {
 	info.AddOption(OPT_QUIET, "Quiet mode")
 	info.AddOption(OPT_IGNORE_PACKAGES, "Do not check system for installed packages")
 	info.AddOption(OPT_NO_CLEANUP, "Disable deleting files created during tests")
+	info.AddOption(OPT_ROLLBACK, "Restore all objects outside of working dir modified by actions")
+	info.AddOption(OPT_CORE_DUMPS, "Save core dumps of crashed processes to errors directory")
+	info.AddOption(OPT_COVERAGE, "Path to directory for coverage data of Go binaries built with -cover flag", "dir")
+	info.AddOption(OPT_WRAPPER, "Run commands under memory checkers {s-}(valgrind|asan|ubsan){!}", "checkers")
+	info.AddOption(OPT_TIMEOUT, "Max duration of recipe processing in seconds", "duration")
+	info.AddOption(OPT_TEARDOWN_TIMEOUT, "Max duration of teardown commands execution after interrupt in seconds {s-}(default: 30){!}", "duration")
+	info.AddOption(OPT_BENCHMARK, "Benchmark mode {s-}(repeat commands with benchmark option){!}")
+	info.AddOption(OPT_BASELINE, "Path to file with benchmark baseline", "file")
+	info.AddOption(OPT_BASELINE_THRESHOLD, "Max regression of mean duration in percents {s-}(default: 10){!}", "percents")
+	info.AddOption(OPT_BASELINE_UPDATE, "Save benchmark results as a new baseline")
 
 	if withSelfUpdate {
 		info.AddOption(OPT_UPDATE, "Update application to the latest version")
@@ -483,11 +670,36 @@ func genUsage() *usage.Info {
 		"Run tests from app.recipe and print the last 50 lines from command output if action was failed",
 	)
 
+	info.AddExample(
+		"app.recipe --rollback --error-dir bibop-errors",
+		"Run tests from app.recipe, restore all modified objects after tests and save rollback report to bibop-errors directory",
+	)
+
+	info.AddExample(
+		"app.recipe --timeout 600 --teardown-timeout 60",
+		"Run tests from app.recipe, interrupt processing after 10 minutes and give teardown commands 1 minute to finish",
+	)
+
+	info.AddExample(
+		"app.recipe --benchmark --baseline app.baseline --baseline-threshold 15",
+		"Run tests from app.recipe in benchmark mode and fail if commands become slower than baseline by more than 15%",
+	)
+
 	info.AddExample(
 		"app.recipe --format json 1> ~/results/app.json",
 		"Run tests from app.recipe and save result in JSON format",
 	)
 
+	info.AddExample(
+		"app.recipe --format json:app.json --format junit:app.xml",
+		"Run tests from app.recipe and save results in JSON and JUnit XML formats to files",
+	)
+
+	info.AddExample(
+		`app.recipe --format "json:'results/app report.json'"`,
+		"Run tests from app.recipe and save result in JSON format to file with spaces in path (paths with spaces, commas or semicolons must be quoted)",
+	)
+
 	info.AddRawExample(
 		"sudo dnf install $(bibop app.recipe -L1)",
 		"Install all packages required for tests",
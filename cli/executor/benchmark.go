@@ -0,0 +1,221 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/timeutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+	"github.com/essentialkaos/bibop/render"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Baseline contains benchmark results used for comparison
+type Baseline struct {
+	Commands []*BaselineRecord `json:"commands"`
+
+	isChanged bool // True if records for new commands were added
+}
+
+// BaselineRecord contains benchmark results for one command
+type BaselineRecord struct {
+	Cmdline     string  `json:"cmdline"`
+	Description string  `json:"description"`
+	Iterations  int     `json:"iterations"`
+	Min         float64 `json:"min"`
+	Mean        float64 `json:"mean"`
+	P95         float64 `json:"p95"`
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// runBenchmark executes command multiple times and compares mean duration with
+// baseline. Actions are rendered only for the first iteration.
+func runBenchmark(e *Executor, rr render.Renderer, c *recipe.Command) bool {
+	var durations []time.Duration
+
+	for i := range c.Iterations {
+		iterRR := rr
+
+		if i > 0 {
+			iterRR = &render.QuietRenderer{}
+		}
+
+		c.Started = time.Now()
+
+		if !runCommand(e, iterRR, c) {
+			if i > 0 && !isInterrupted(e) {
+				rr.CommandFailed(c, fmt.Errorf("Benchmark iteration %d failed", i+1))
+			}
+
+			return false
+		}
+
+		durations = append(durations, time.Since(c.Started))
+	}
+
+	c.Benchmark = getBenchmarkResult(durations)
+
+	err := checkBaseline(e, c)
+
+	if err != nil {
+		rr.CommandFailed(c, err)
+		logError(e, c, nil, nil, err)
+		return false
+	}
+
+	return true
+}
+
+// checkBaseline compares benchmark results with baseline and adds results to
+// the new baseline
+func checkBaseline(e *Executor, c *recipe.Command) error {
+	if e.config.BaselineFile == "" {
+		return nil
+	}
+
+	record := &BaselineRecord{
+		Cmdline:     c.GetCmdline(),
+		Description: c.Description,
+		Iterations:  c.Benchmark.Iterations,
+		Min:         c.Benchmark.Min.Seconds(),
+		Mean:        c.Benchmark.Mean.Seconds(),
+		P95:         c.Benchmark.P95.Seconds(),
+	}
+
+	e.benchmarks = append(e.benchmarks, record)
+
+	if e.baseline == nil {
+		return nil
+	}
+
+	baseRecord := e.baseline.Find(record.Cmdline, record.Description)
+
+	if baseRecord == nil {
+		// Commands without results in baseline are added to it
+		e.baseline.Commands = append(e.baseline.Commands, record)
+		e.baseline.isChanged = true
+		return nil
+	}
+
+	if baseRecord.Mean <= 0 {
+		return nil
+	}
+
+	threshold := e.config.BaselineThreshold
+
+	c.Benchmark.BaselineMean = timeutil.SecondsToDuration(baseRecord.Mean)
+	diff := (record.Mean - baseRecord.Mean) / baseRecord.Mean * 100.0
+
+	if diff > threshold {
+		return fmt.Errorf(
+			"Mean duration %s is %.1f%% greater than baseline %s (threshold: %g%%)",
+			timeutil.PrettyDuration(c.Benchmark.Mean), diff,
+			timeutil.PrettyDuration(c.Benchmark.BaselineMean), threshold,
+		)
+	}
+
+	return nil
+}
+
+// loadBaseline loads baseline from file. If baseline must be updated or file
+// doesn't exist, nil will be returned.
+func loadBaseline(e *Executor) error {
+	if e.config.BaselineFile == "" || e.config.BaselineUpdate {
+		return nil
+	}
+
+	data, err := os.ReadFile(e.config.BaselineFile)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("Can't read baseline file: %v", err)
+	}
+
+	baseline := &Baseline{}
+	err = json.Unmarshal(data, baseline)
+
+	if err != nil {
+		return fmt.Errorf("Can't parse baseline file: %v", err)
+	}
+
+	e.baseline = baseline
+
+	return nil
+}
+
+// saveBaseline saves benchmark results to baseline file if file doesn't exist
+// or baseline must be updated. If baseline exists, only results for new commands
+// will be added to it.
+func saveBaseline(e *Executor) error {
+	baseline := e.baseline
+
+	switch {
+	case e.config.BaselineFile == "",
+		baseline != nil && !baseline.isChanged,
+		baseline == nil && len(e.benchmarks) == 0:
+		return nil
+	case baseline == nil:
+		baseline = &Baseline{Commands: e.benchmarks}
+	}
+
+	data, _ := json.MarshalIndent(baseline, "", "  ")
+	err := os.WriteFile(e.config.BaselineFile, append(data, '\n'), 0644)
+
+	if err != nil {
+		return fmt.Errorf("Can't save baseline file: %v", err)
+	}
+
+	return nil
+}
+
+// getBenchmarkResult calculates benchmark results
+func getBenchmarkResult(durations []time.Duration) *recipe.BenchmarkResult {
+	var total time.Duration
+
+	durations = slices.Clone(durations)
+	slices.Sort(durations)
+
+	for _, d := range durations {
+		total += d
+	}
+
+	p95Index := int(math.Ceil(float64(len(durations))*0.95)) - 1
+
+	return &recipe.BenchmarkResult{
+		Iterations: len(durations),
+		Min:        durations[0],
+		Mean:       total / time.Duration(len(durations)),
+		P95:        durations[max(p95Index, 0)],
+	}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Find returns baseline record for command with given command line and
+// description
+func (b *Baseline) Find(cmdline, description string) *BaselineRecord {
+	for _, record := range b.Commands {
+		if record.Cmdline == cmdline && record.Description == description {
+			return record
+		}
+	}
+
+	return nil
+}
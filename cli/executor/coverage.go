@@ -0,0 +1,206 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/essentialkaos/ek/v13/fsutil"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// COVERAGE_PROFILE is name of file with coverage profile in text format
+const COVERAGE_PROFILE = "coverage.out"
+
+// COVERAGE_SUMMARY is name of file with coverage summary
+const COVERAGE_SUMMARY = "coverage.txt"
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// setupCoverage creates directory for raw coverage data of Go binaries
+func setupCoverage(e *Executor) error {
+	if !isToolAvailable("go") {
+		return fmt.Errorf("Can't collect coverage data: go is not installed")
+	}
+
+	_, err := getTempDir()
+
+	if err != nil {
+		return err
+	}
+
+	// Coverage data of all commands is collected into one directory, and commands
+	// in the same recipe can be executed by different users, so the directory
+	// must be writable by everyone and can't be placed inside private directory
+	// with other temporary data.
+	coverDir, err := temp.MkDir("coverage")
+
+	if err != nil {
+		return fmt.Errorf("Can't create directory for coverage data: %v", err)
+	}
+
+	err = os.Chmod(coverDir, 0777)
+
+	if err != nil {
+		return fmt.Errorf("Can't set permissions for coverage data directory: %v", err)
+	}
+
+	e.coverDir = coverDir
+
+	return nil
+}
+
+// setCoverageEnv sets directory for coverage data for command
+func setCoverageEnv(cmd *exec.Cmd, coverDir string) {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+
+	cmd.Env = append(cmd.Env, "GOCOVERDIR="+coverDir)
+}
+
+// mergeCoverage merges coverage data from all commands, creates profile and
+// summary and returns summary
+func mergeCoverage(e *Executor) (string, error) {
+	rawData, _ := os.ReadDir(e.coverDir)
+
+	if len(rawData) == 0 {
+		return "", fmt.Errorf("Can't save coverage data: there is no coverage data (binaries must be built with -cover flag)")
+	}
+
+	tempDir, err := getTempDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	mergeDir := tempDir + "/coverage-merged"
+	err = os.Mkdir(mergeDir, 0700)
+
+	if err != nil {
+		return "", fmt.Errorf("Can't create directory for merged coverage data: %v", err)
+	}
+
+	_, err = runCovdata("merge", "-i="+e.coverDir, "-o="+mergeDir)
+
+	if err != nil {
+		return "", fmt.Errorf("Can't merge coverage data: %v", err)
+	}
+
+	_, err = runCovdata("textfmt", "-i="+mergeDir, "-o="+mergeDir+"/"+COVERAGE_PROFILE)
+
+	if err != nil {
+		return "", fmt.Errorf("Can't save coverage profile: %v", err)
+	}
+
+	summary, err := runCovdata("percent", "-i="+mergeDir)
+
+	if err != nil {
+		return "", fmt.Errorf("Can't calculate coverage: %v", err)
+	}
+
+	err = os.WriteFile(mergeDir+"/"+COVERAGE_SUMMARY, summary, 0644)
+
+	if err != nil {
+		return "", fmt.Errorf("Can't save coverage summary: %v", err)
+	}
+
+	e.coverMergeDir = mergeDir
+
+	return strings.TrimSpace(string(summary)), nil
+}
+
+// saveCoverage saves merged coverage data, profile and summary to coverage
+// directory
+func saveCoverage(e *Executor) error {
+	outDir := e.config.CoverageDir
+	err := os.MkdirAll(outDir, 0755)
+
+	if err != nil {
+		return fmt.Errorf("Can't create coverage directory: %v", err)
+	}
+
+	err = removeCoverageData(outDir)
+
+	if err != nil {
+		return fmt.Errorf("Can't remove coverage data from previous run: %v", err)
+	}
+
+	files, err := os.ReadDir(e.coverMergeDir)
+
+	if err != nil {
+		return fmt.Errorf("Can't read merged coverage data: %v", err)
+	}
+
+	for _, file := range files {
+		err = fsutil.CopyFile(
+			e.coverMergeDir+"/"+file.Name(),
+			outDir+"/"+file.Name(), 0644,
+		)
+
+		if err != nil {
+			return fmt.Errorf("Can't save coverage data: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// removeCoverageData removes coverage data, profile and summary from given
+// directory
+func removeCoverageData(dir string) error {
+	files, err := os.ReadDir(dir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		name := file.Name()
+
+		if file.IsDir() || (name != COVERAGE_PROFILE && name != COVERAGE_SUMMARY &&
+			!strings.HasPrefix(name, "covmeta.") &&
+			!strings.HasPrefix(name, "covcounters.")) {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(dir, name))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runCovdata runs "go tool covdata" with given arguments
+func runCovdata(args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("go", append([]string{"tool", "covdata"}, args...)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err := cmd.Run()
+
+	if err != nil {
+		if stderr.Len() != 0 {
+			return nil, fmt.Errorf("%s", strings.TrimSpace(stderr.String()))
+		}
+
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,284 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/fsutil"
+
+	"github.com/essentialkaos/bibop/action"
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// CORE_PATTERN is path to file with kernel core dump file name pattern
+const CORE_PATTERN = "/proc/sys/kernel/core_pattern"
+
+// CORE_USES_PID is path to file with kernel option for adding PID to core dump
+// file name
+const CORE_USES_PID = "/proc/sys/kernel/core_uses_pid"
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// enableCoreDumps removes limit for core dumps size for all commands
+func enableCoreDumps() error {
+	rlimit := &syscall.Rlimit{}
+	err := syscall.Getrlimit(syscall.RLIMIT_CORE, rlimit)
+
+	if err != nil {
+		return fmt.Errorf("Can't enable core dumps: %v", err)
+	}
+
+	rlimit.Cur = rlimit.Max
+
+	// Only privileged user can raise hard limit
+	if os.Getuid() == 0 {
+		rlimit.Cur, rlimit.Max = ^uint64(0), ^uint64(0)
+	}
+
+	err = syscall.Setrlimit(syscall.RLIMIT_CORE, rlimit)
+
+	if err != nil {
+		return fmt.Errorf("Can't enable core dumps: %v", err)
+	}
+
+	return nil
+}
+
+// checkCrash saves info about command process crash if process is finished
+func checkCrash(c *recipe.Command, cmdEnv *CommandEnv) {
+	if cmdEnv == nil || cmdEnv.cmd.ProcessState == nil {
+		return
+	}
+
+	// Process state is set right before the channel is closed
+	<-cmdEnv.exited
+
+	if c.User != "" {
+		// runuser reports the signal which killed the command as exit code
+		c.Crash = action.GetWrappedCrashInfo(cmdEnv.cmd)
+	} else {
+		c.Crash = action.GetCrashInfo(cmdEnv.cmd)
+	}
+}
+
+// saveCrashReport saves report with info about crash, core dump and stack trace
+// into errors directory
+func saveCrashReport(e *Executor, c *recipe.Command, ce *CommandEnv, recipeName string, ts int64) {
+	var report bytes.Buffer
+
+	pid := ce.cmd.Process.Pid
+
+	fmt.Fprintf(&report, "Command: %s\n", c.GetCmdline())
+	fmt.Fprintf(&report, "Binary:  %s\n", ce.cmd.Path)
+	fmt.Fprintf(&report, "PID:     %d\n", pid)
+	fmt.Fprintf(&report, "Signal:  %s\n", c.Crash.Signal)
+
+	switch {
+	case c.User != "":
+		report.WriteString("Core:    unknown (command was executed by runuser)\n")
+	case !c.Crash.CoreDumped:
+		report.WriteString("Core:    not dumped (check core size limit)\n")
+	case !e.config.CoreDumps:
+		report.WriteString("Core:    not saved (use --core-dumps option)\n")
+	default:
+		coreFile := fmt.Sprintf("%s/%s-core-%d", e.config.ErrsDir, recipeName, ts)
+		err := saveCoreDump(pid, ce.cmd.Path, coreFile)
+
+		if err != nil {
+			fmt.Fprintf(&report, "Core:    not saved (%v)\n", err)
+		} else {
+			c.Crash.CoreFile = coreFile
+			fmt.Fprintf(&report, "Core:    %s\n", coreFile)
+		}
+	}
+
+	if !ce.output.IsEmpty() {
+		fmt.Fprintf(&report, "\nThe last %d lines from command output:\n\n", e.config.DebugLines)
+		report.WriteString(ce.output.Tail(e.config.DebugLines))
+		report.WriteString("\n")
+	}
+
+	if c.Crash.CoreFile != "" {
+		trace, tool := getStackTrace(ce.cmd.Path, c.Crash.CoreFile)
+
+		if trace != "" {
+			fmt.Fprintf(&report, "\nStack trace (%s):\n\n%s\n", tool, trace)
+		}
+	}
+
+	reportFile := fmt.Sprintf("%s/%s-crash-%d.log", e.config.ErrsDir, recipeName, ts)
+	err := os.WriteFile(reportFile, report.Bytes(), 0644)
+
+	if err != nil {
+		e.logger.Info("Can't save crash report: %v", err)
+	}
+}
+
+// saveCoreDump finds core dump of process with given PID and saves it to given
+// file
+func saveCoreDump(pid int, binary, coreFile string) error {
+	pattern, err := os.ReadFile(CORE_PATTERN)
+
+	if err != nil {
+		return fmt.Errorf("can't read core pattern: %v", err)
+	}
+
+	corePattern := strings.TrimSpace(string(pattern))
+
+	// Core dumps are handled by systemd-coredump or other helper
+	if strings.HasPrefix(corePattern, "|") {
+		return saveCoreDumpFromJournal(pid, coreFile)
+	}
+
+	files, _ := filepath.Glob(getCoreDumpGlob(corePattern, pid, binary))
+
+	if len(files) == 0 {
+		return fmt.Errorf("can't find core dump using pattern %q", corePattern)
+	}
+
+	// Use the newest file if there are more than one matching file
+	coreDump := files[0]
+
+	for _, file := range files[1:] {
+		mtime1, _ := fsutil.GetMTime(coreDump)
+		mtime2, _ := fsutil.GetMTime(file)
+
+		if mtime2.After(mtime1) {
+			coreDump = file
+		}
+	}
+
+	err = fsutil.MoveFile(coreDump, coreFile, 0640)
+
+	if err != nil {
+		return fmt.Errorf("can't move core dump: %v", err)
+	}
+
+	return nil
+}
+
+// saveCoreDumpFromJournal saves core dump stored by systemd-coredump
+func saveCoreDumpFromJournal(pid int, coreFile string) error {
+	_, err := exec.LookPath("coredumpctl")
+
+	if err != nil {
+		return fmt.Errorf("core dumps are handled by helper and coredumpctl is not available")
+	}
+
+	// Helper can process core dump with some delay
+	for range 20 {
+		err = exec.Command(
+			"coredumpctl", "dump", strconv.Itoa(pid),
+			"--output", coreFile, "--quiet", "--no-pager",
+		).Run()
+
+		if err == nil {
+			return nil
+		}
+
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	return fmt.Errorf("can't get core dump using coredumpctl: %v", err)
+}
+
+// getCoreDumpGlob converts core pattern to glob pattern
+func getCoreDumpGlob(pattern string, pid int, binary string) string {
+	var result strings.Builder
+
+	hasPID := false
+	pidStr := strconv.Itoa(pid)
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			result.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+
+		switch pattern[i] {
+		case '%':
+			result.WriteByte('%')
+		case 'p', 'P':
+			result.WriteString(pidStr)
+			hasPID = true
+		case 'e':
+			result.WriteString(getCommName(binary))
+		case 'E':
+			result.WriteString(strings.ReplaceAll(binary, "/", "!"))
+		default:
+			result.WriteString("*")
+		}
+	}
+
+	usesPID, _ := os.ReadFile(CORE_USES_PID)
+
+	if !hasPID && strings.TrimSpace(string(usesPID)) == "1" {
+		result.WriteString("." + pidStr)
+	}
+
+	if !filepath.IsAbs(result.String()) {
+		cwd, _ := os.Getwd()
+		return filepath.Join(cwd, result.String())
+	}
+
+	return result.String()
+}
+
+// getCommName returns process name used by kernel (limited to 15 symbols)
+func getCommName(binary string) string {
+	name := filepath.Base(binary)
+
+	if len(name) > 15 {
+		return name[:15]
+	}
+
+	return name
+}
+
+// getStackTrace returns stack trace from core dump using gdb or eu-stack
+func getStackTrace(binary, coreFile string) (string, string) {
+	var cmd *exec.Cmd
+	var tool string
+
+	switch {
+	case isToolAvailable("gdb"):
+		tool = "gdb"
+		cmd = exec.Command(
+			"gdb", "--batch", "--nx", "--quiet",
+			"--ex", "thread apply all bt", binary, coreFile,
+		)
+	case isToolAvailable("eu-stack"):
+		tool = "eu-stack"
+		cmd = exec.Command("eu-stack", "--executable", binary, "--core", coreFile)
+	default:
+		return "", ""
+	}
+
+	output, _ := cmd.CombinedOutput()
+
+	return strings.TrimSpace(string(output)), tool
+}
+
+// isToolAvailable returns true if given tool is available in PATH
+func isToolAvailable(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -12,8 +12,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"slices"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -39,6 +42,9 @@ import (
 
 const MAX_STORAGE_SIZE = 8 * 1024 * 1024 // 8 MB
 
+// STOP_TIMEOUT is max time for command process termination before killing it
+const STOP_TIMEOUT = 10 * time.Second
+
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 // Executor is executor struct
@@ -48,8 +54,22 @@ type Executor struct {
 	passes     int             // Number of passed commands
 	fails      int             // Number of failed commands
 	skipped    int             // Number of skipped commands
+	hookFails  int             // Number of failed hooks
 	logger     *log.Logger     // Pointer to logger
 	wrkDirObjs map[string]bool // Map with working dir objects
+
+	tracker *action.ChangeTracker // Filesystem changes tracker
+
+	baseline   *Baseline         // Benchmark results used for comparison
+	benchmarks []*BaselineRecord // Benchmark results
+
+	coverDir      string // Directory for raw coverage data
+	coverMergeDir string // Directory for merged coverage data
+
+	interrupted     chan bool  // Channel closed on recipe processing interruption
+	aborted         chan bool  // Channel closed on teardown commands abortion
+	interruptReason string     // Reason of recipe processing interruption
+	mx              sync.Mutex // Mutex for interruption info
 }
 
 // ExecutorConfig contains executor configuration
@@ -59,6 +79,17 @@ type Config struct {
 	DebugLines     int
 	Quiet          bool
 	DisableCleanup bool
+	Rollback       bool
+	CoreDumps      bool
+	CoverageDir    string
+
+	Timeout         float64
+	TeardownTimeout float64
+
+	Benchmark         bool
+	BaselineFile      string
+	BaselineThreshold float64
+	BaselineUpdate    bool
 }
 
 // ValidationConfig is config for validation
@@ -71,9 +102,11 @@ type ValidationConfig struct {
 
 // CommandEnv is command env
 type CommandEnv struct {
-	cmd    *exec.Cmd
-	output *action.OutputContainer
-	term   *PTY
+	cmd     *exec.Cmd
+	output  *action.OutputContainer
+	term    *PTY
+	exited  chan bool
+	reports string
 }
 
 // PTY contains pseudo-terminal structs
@@ -146,6 +179,49 @@ var handlers = map[string]action.Handler{
 	recipe.ACTION_PYTHON2_PACKAGE: action.Python2Package,
 	recipe.ACTION_PYTHON3_PACKAGE: action.Python3Package,
 	recipe.ACTION_TEMPLATE:        action.Template,
+
+	recipe.ACTION_SNAPSHOT:         action.Snapshot,
+	recipe.ACTION_SNAPSHOT_CREATED: action.SnapshotCreated,
+	recipe.ACTION_SNAPSHOT_DELETED: action.SnapshotDeleted,
+	recipe.ACTION_SNAPSHOT_CHANGED: action.SnapshotChanged,
+
+	recipe.ACTION_PROCESS_EXIST:  action.ProcessExist,
+	recipe.ACTION_PROCESS_COUNT:  action.ProcessCount,
+	recipe.ACTION_PROCESS_USER:   action.ProcessUser,
+	recipe.ACTION_PROCESS_PARENT: action.ProcessParent,
+	recipe.ACTION_PROCESS_STATE:  action.ProcessState,
+	recipe.ACTION_WAIT_PROCESS:   action.WaitProcess,
+
+	recipe.ACTION_LISTEN:       action.Listen,
+	recipe.ACTION_LISTEN_OWNER: action.ListenOwner,
+	recipe.ACTION_LISTEN_LOCAL: action.ListenLocal,
+
+	recipe.ACTION_FILE_CAP:            action.FileCap,
+	recipe.ACTION_FILE_CAP_SET:        action.FileCapSet,
+	recipe.ACTION_XATTR:               action.Xattr,
+	recipe.ACTION_XATTR_SET:           action.XattrSet,
+	recipe.ACTION_SELINUX_CONTEXT:     action.SELinuxContext,
+	recipe.ACTION_SELINUX_CONTEXT_SET: action.SELinuxContextSet,
+	recipe.ACTION_FILE_ATTR:           action.FileAttr,
+	recipe.ACTION_FILE_ATTR_SET:       action.FileAttrSet,
+
+	recipe.ACTION_ACL:         action.ACL,
+	recipe.ACTION_ACL_DEFAULT: action.ACLDefault,
+
+	recipe.ACTION_SERVICE_PROPERTY:          action.ServiceProperty,
+	recipe.ACTION_SERVICE_PROPERTY_CONTAINS: action.ServicePropertyContains,
+	recipe.ACTION_JOURNAL_CONTAINS:          action.JournalContains,
+	recipe.ACTION_JOURNAL_MATCH:             action.JournalMatch,
+
+	recipe.ACTION_LOG_CONTAINS:   action.LogContains,
+	recipe.ACTION_LOG_MATCH:      action.LogMatch,
+	recipe.ACTION_WAIT_LOG:       action.WaitLog,
+	recipe.ACTION_WAIT_LOG_MATCH: action.WaitLogMatch,
+
+	recipe.ACTION_WAIT_FS_MODIFY:   action.WaitFSModify,
+	recipe.ACTION_WAIT_FS_SIZE:     action.WaitFSSize,
+	recipe.ACTION_WAIT_FS_SETTLE:   action.WaitFSSettle,
+	recipe.ACTION_WAIT_FS_CONTAINS: action.WaitFSContains,
 }
 
 var temp *tmp.Temp
@@ -155,7 +231,11 @@ var tempDir string
 
 // NewExecutor create new executor struct
 func NewExecutor(cfg *Config) *Executor {
-	return &Executor{config: cfg}
+	return &Executor{
+		config:      cfg,
+		interrupted: make(chan bool),
+		aborted:     make(chan bool),
+	}
 }
 
 // ////////////////////////////////////////////////////////////////////////////////// //
@@ -199,17 +279,106 @@ func (e *Executor) Run(rr render.Renderer, r *recipe.Recipe, tags []string) bool
 
 	e.wrkDirObjs = getWorkingDirObjects(r.Dir)
 
+	if e.config.CoreDumps {
+		err := enableCoreDumps()
+
+		if err != nil {
+			fmtc.Fprintf(os.Stderr, "{r}%v{!}\n", err)
+			return false
+		}
+	}
+
+	if e.config.Rollback {
+		err := setupChangeTracker(e)
+
+		if err != nil {
+			fmtc.Fprintf(os.Stderr, "{r}%v{!}\n", err)
+			return false
+		}
+	}
+
+	if e.config.Benchmark {
+		err := loadBaseline(e)
+
+		if err != nil {
+			fmtc.Fprintf(os.Stderr, "{r}%v{!}\n", err)
+			return false
+		}
+	}
+
+	if e.config.CoverageDir != "" {
+		err := setupCoverage(e)
+
+		if err != nil {
+			fmtc.Fprintf(os.Stderr, "{r}%v{!}\n", err)
+			return false
+		}
+	}
+
+	sigChan := make(chan os.Signal, 1)
+
+	// Signals are handled until the end of processing, so rollback and restoring
+	// of backups can't be interrupted
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
 	applyRecipeOptions(e, rr, r)
-	processRecipe(e, rr, r, tags)
+	runRecipe(e, rr, r, tags, sigChan)
 
 	os.Chdir(cwd)
 
+	if r.AutoRestore {
+		restoreBackups(e, r)
+	}
+
+	rollbackChanges(e, r)
+
+	interruptReason := getInterruptReason(e)
+
+	if interruptReason != "" {
+		rr.Interrupted(interruptReason)
+	}
+
+	// Coverage data must be merged before printing result, so the summary
+	// can be added to the report
+	if e.coverDir != "" {
+		summary, err := mergeCoverage(e)
+
+		if err != nil && !e.config.Quiet {
+			fmtc.Fprintf(os.Stderr, "{r}%v{!}\n", err)
+		}
+
+		if summary != "" {
+			rr.Coverage(summary)
+		}
+	}
+
 	rr.Result(e.passes, e.fails, e.skipped)
 
-	cleanTempData()
+	removeCgroups()
 	cleanupWorkingDir(e, r.Dir)
 
-	return e.fails == 0
+	// Baseline and coverage data must be saved after working dir cleanup,
+	// because they may be created in working dir
+	if e.config.Benchmark {
+		err := saveBaseline(e)
+
+		if err != nil && !e.config.Quiet {
+			fmtc.Fprintf(os.Stderr, "{r}%v{!}\n", err)
+		}
+	}
+
+	if e.coverMergeDir != "" {
+		err := saveCoverage(e)
+
+		if err != nil && !e.config.Quiet {
+			fmtc.Fprintf(os.Stderr, "{r}%v{!}\n", err)
+		}
+	}
+
+	cleanTempData()
+
+	return e.fails == 0 && e.hookFails == 0 && interruptReason == ""
 }
 
 // ////////////////////////////////////////////////////////////////////////////////// //
@@ -243,10 +412,15 @@ func processRecipe(e *Executor, rr render.Renderer, r *recipe.Recipe, tags []str
 	var finished bool
 
 	lastSkippedGroupID := recipe.MAX_GROUP_ID
+	hookGroupID := -1 // ID of command group wrapped by hooks
 
 	e.start = time.Now()
 	e.skipped = len(r.Commands)
 
+	if !runHooks(e, rr, r.Setup) {
+		finished = true // Only teardown commands will be executed
+	}
+
 	for index, command := range r.Commands {
 		if r.LockWorkdir && r.Dir != "" {
 			os.Chdir(r.Dir) // Set current dir to working dir for every command
@@ -254,16 +428,39 @@ func processRecipe(e *Executor, rr render.Renderer, r *recipe.Recipe, tags []str
 
 		isLastCommand := index+1 == len(r.Commands)
 
-		if skipCommand(command, tags, lastSkippedGroupID, finished) {
+		if hookGroupID != -1 && hookGroupID != int(command.GroupID) {
+			runHooks(e, rr, r.AfterEach)
+			hookGroupID = -1
+		}
+
+		if isTeardownAborted(e) ||
+			skipCommand(command, tags, lastSkippedGroupID, finished || isInterrupted(e)) {
 			lastSkippedGroupID = command.GroupID
 			rr.CommandSkipped(command, isLastCommand)
 			continue
 		}
 
+		if command.Tag != recipe.TEARDOWN_TAG && hookGroupID == -1 &&
+			len(r.BeforeEach)+len(r.AfterEach) != 0 {
+			hookGroupID = int(command.GroupID)
+
+			if !runHooks(e, rr, r.BeforeEach) {
+				lastSkippedGroupID = command.GroupID
+				rr.CommandSkipped(command, isLastCommand)
+				continue
+			}
+		}
+
+		var ok bool
+
 		command.Started = time.Now()
 		rr.CommandStarted(command)
 
-		ok := runCommand(e, rr, command)
+		if e.config.Benchmark && command.Iterations > 1 {
+			ok = runBenchmark(e, rr, command)
+		} else {
+			ok = runCommand(e, rr, command)
+		}
 
 		e.skipped--
 
@@ -291,32 +488,72 @@ func processRecipe(e *Executor, rr render.Renderer, r *recipe.Recipe, tags []str
 			time.Sleep(timeutil.SecondsToDuration(r.Delay))
 		}
 	}
+
+	if hookGroupID != -1 {
+		runHooks(e, rr, r.AfterEach)
+	}
 }
 
 // runCommand executes command and all actions
 func runCommand(e *Executor, rr render.Renderer, c *recipe.Command) bool {
+	_, err := runCommandActions(e, rr, c)
+	return err == nil
+}
+
+// runCommandActions executes command and all actions and returns failed action
+// (if any) and error
+func runCommandActions(e *Executor, rr render.Renderer, c *recipe.Command) (*recipe.Action, error) {
 	var err error
 	var cmdEnv *CommandEnv
 
+	action.SaveFSState(c)
+	action.StartLogFollowing(c)
+	defer action.StopLogFollowing(c)
+
 	if !c.IsHollow() {
-		cmdEnv, err = execCommand(c)
+		cmdEnv, err = execCommand(e, c)
 
 		if err != nil {
 			rr.CommandFailed(c, err)
 
 			logError(e, c, nil, cmdEnv, err)
 
-			return false
+			return nil, err
 		}
+
+		defer collectResourceUsage(c, cmdEnv)
 	}
 
+	defer watchCommand(e, c, cmdEnv)()
+
 	for index, action := range c.Actions {
+		err = getAbortError(e, c)
+
+		if err != nil {
+			err = finishCommand(c, cmdEnv, err)
+			collectOutput(e, c, cmdEnv)
+			rr.CommandFailed(c, err)
+
+			logError(e, c, nil, cmdEnv, err)
+
+			return nil, err
+		}
+
 		action.Started = time.Now()
 		rr.ActionStarted(action)
 
-		err = runAction(action, cmdEnv)
+		err = runInterruptibleAction(e, action, cmdEnv)
+
+		if err == nil {
+			err = checkDeadline(c)
+		}
+
+		if err != nil || index+1 == len(c.Actions) {
+			err = finishCommand(c, cmdEnv, err)
+		}
 
 		if err != nil {
+			collectOutput(e, c, cmdEnv)
 			rr.ActionFailed(action, err)
 		} else {
 			rr.ActionDone(action, index+1 == len(c.Actions))
@@ -333,25 +570,56 @@ func runCommand(e *Executor, rr render.Renderer, c *recipe.Command) bool {
 			}
 
 			logError(e, c, action, cmdEnv, err)
-			return false
+			return action, err
 		}
 	}
 
-	return true
+	if len(c.Actions) == 0 {
+		// There are no actions for checking the process, so we wait until it
+		// exits on its own to collect complete memory checkers reports
+		if cmdEnv != nil && cmdEnv.reports != "" {
+			<-cmdEnv.exited
+		}
+
+		err = finishCommand(c, cmdEnv, getAbortError(e, c))
+
+		if err != nil {
+			collectOutput(e, c, cmdEnv)
+			rr.CommandFailed(c, err)
+
+			logError(e, c, nil, cmdEnv, err)
+
+			return nil, err
+		}
+	}
+
+	return nil, nil
 }
 
 // execCommand executes command
-func execCommand(c *recipe.Command) (*CommandEnv, error) {
+func execCommand(e *Executor, c *recipe.Command) (*CommandEnv, error) {
 	var err error
 
-	cmdEnv := &CommandEnv{}
+	cmdEnv := &CommandEnv{exited: make(chan bool)}
 
-	cmdEnv.cmd, err = createCommand(c)
+	if c.GetWrapper().IsEnabled() {
+		cmdEnv.reports, err = getReportsPrefix(c)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cmdEnv.cmd, err = createCommand(c, cmdEnv.reports)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if e.coverDir != "" {
+		setCoverageEnv(cmdEnv.cmd, e.coverDir)
+	}
+
 	cmdEnv.term, err = createPTY(cmdEnv.cmd)
 
 	if err != nil {
@@ -360,23 +628,71 @@ func execCommand(c *recipe.Command) (*CommandEnv, error) {
 
 	cmdEnv.output = action.NewOutputContainer(MAX_STORAGE_SIZE)
 
+	limits := c.GetLimits()
+
+	if limits.HasCgroupLimits() {
+		cgroupDir, cgroupFD, err := createCgroup(cmdEnv.cmd, limits)
+
+		if err != nil {
+			cmdEnv.term.Close()
+			return nil, err
+		}
+
+		defer syscall.Close(cgroupFD)
+
+		c.Data.Set(action.PROP_CGROUP, cgroupDir)
+	}
+
 	go outputIOLoop(cmdEnv)
 
-	err = cmdEnv.cmd.Start()
+	if c.GetIsolation().IsEnabled() {
+		err = startIsolatedCommand(cmdEnv.cmd, c.GetIsolation(), limits, getSharedDirs(e, cmdEnv))
+	} else {
+		err = startCommand(cmdEnv.cmd, limits)
+	}
 
 	if err != nil {
 		cmdEnv.term.Close()
 		return nil, err
 	}
 
-	go cmdEnv.cmd.Wait()
+	go func() {
+		cmdEnv.cmd.Wait()
+		close(cmdEnv.exited)
+	}()
 
 	return cmdEnv, nil
 }
 
+// getSharedDirs returns directories which must be available for isolated command
+func getSharedDirs(e *Executor, cmdEnv *CommandEnv) []string {
+	var result []string
+
+	if e.coverDir != "" {
+		result = append(result, e.coverDir)
+	}
+
+	if cmdEnv.reports != "" {
+		result = append(result, filepath.Dir(cmdEnv.reports))
+	}
+
+	return result
+}
+
 // createCommand creates command
-func createCommand(c *recipe.Command) (*exec.Cmd, error) {
-	var cmdSlice []string
+func createCommand(c *recipe.Command, reports string) (*exec.Cmd, error) {
+	var cmdSlice, wrapperArgs, wrapperEnv []string
+
+	wrapper := c.GetWrapper()
+
+	if wrapper.IsEnabled() {
+		if wrapper.Valgrind && !isToolAvailable("valgrind") {
+			return nil, fmt.Errorf("Can't execute the command: valgrind is not installed")
+		}
+
+		wrapperArgs = getWrapperArgs(wrapper, reports)
+		wrapperEnv = getWrapperEnv(c, wrapper, reports)
+	}
 
 	if c.User != "" {
 		if !system.IsUserExist(c.User) {
@@ -384,35 +700,50 @@ func createCommand(c *recipe.Command) (*exec.Cmd, error) {
 		}
 
 		cmdSlice = append(cmdSlice, "/sbin/runuser", "-s", "/bin/bash", c.User, "-c")
+		cmdline := c.GetCmdline()
+
+		if len(wrapperArgs) != 0 {
+			cmdline = strings.Join(wrapperArgs, " ") + " " + cmdline
+		}
 
 		if c.Recipe.Unbuffer {
-			cmdSlice = append(cmdSlice, "stdbuf -o0 -e0 -i0 "+c.GetCmdline())
+			cmdSlice = append(cmdSlice, "stdbuf -o0 -e0 -i0 "+cmdline)
 		} else {
-			cmdSlice = append(cmdSlice, c.GetCmdline())
+			cmdSlice = append(cmdSlice, cmdline)
 		}
 	} else {
 		if c.Recipe.Unbuffer {
 			cmdSlice = append(cmdSlice, "stdbuf", "-o0", "-e0", "-i0")
 		}
 
+		cmdSlice = append(cmdSlice, wrapperArgs...)
 		cmdSlice = append(cmdSlice, c.GetCmdlineArgs()...)
 	}
 
 	cmd := exec.Command(cmdSlice[0], cmdSlice[1:]...)
 
-	if len(c.Env) != 0 {
+	if len(c.Env) != 0 || len(wrapperEnv) != 0 {
 		cmd.Env = append(os.Environ(), c.Env...)
+		cmd.Env = append(cmd.Env, wrapperEnv...)
 	}
 
 	return cmd, nil
 }
 
 // runAction run action on command
-func runAction(a *recipe.Action, cmdEnv *CommandEnv) error {
+func runAction(e *Executor, a *recipe.Action, cmdEnv *CommandEnv) error {
 	var err error
 	var tmpDir string
 
-	if a.Name == recipe.ACTION_BACKUP || a.Name == recipe.ACTION_BACKUP_RESTORE {
+	if e.tracker != nil {
+		err = trackChanges(e, a)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.Name == recipe.ACTION_BACKUP {
 		tmpDir, err = getTempDir()
 
 		if err != nil {
@@ -426,10 +757,12 @@ func runAction(a *recipe.Action, cmdEnv *CommandEnv) error {
 	}
 
 	switch a.Name {
-	case recipe.ACTION_EXIT, recipe.ACTION_EXPECT, recipe.ACTION_PRINT,
+	case recipe.ACTION_EXIT, recipe.ACTION_RUNNING, recipe.ACTION_EXPECT, recipe.ACTION_PRINT,
 		recipe.ACTION_WAIT_OUTPUT, recipe.ACTION_OUTPUT_CONTAINS,
 		recipe.ACTION_OUTPUT_EMPTY, recipe.ACTION_OUTPUT_MATCH,
-		recipe.ACTION_OUTPUT_TRIM, recipe.ACTION_SIGNAL:
+		recipe.ACTION_OUTPUT_TRIM, recipe.ACTION_SIGNAL, recipe.ACTION_MAX_RSS,
+		recipe.ACTION_MAX_CPU_TIME, recipe.ACTION_MAX_IO_READ, recipe.ACTION_MAX_IO_WRITE,
+		recipe.ACTION_MAX_DURATION:
 
 		if cmdEnv == nil {
 			return fmt.Errorf("Action %q doesn't support hollow commands (without executing binary)", a.Name)
@@ -439,6 +772,8 @@ func runAction(a *recipe.Action, cmdEnv *CommandEnv) error {
 	switch a.Name {
 	case recipe.ACTION_EXIT:
 		return action.Exit(a, cmdEnv.cmd)
+	case recipe.ACTION_RUNNING:
+		return action.Running(a, cmdEnv.cmd)
 	case recipe.ACTION_EXPECT:
 		return action.Expect(a, cmdEnv.output)
 	case recipe.ACTION_PRINT:
@@ -456,9 +791,43 @@ func runAction(a *recipe.Action, cmdEnv *CommandEnv) error {
 	case recipe.ACTION_BACKUP:
 		return action.Backup(a, tmpDir)
 	case recipe.ACTION_BACKUP_RESTORE:
-		return action.BackupRestore(a, tmpDir)
+		return action.BackupRestore(a)
 	case recipe.ACTION_SIGNAL:
 		return action.Signal(a, cmdEnv.cmd)
+	case recipe.ACTION_MAX_RSS:
+		return action.MaxRSS(a, cmdEnv.cmd)
+	case recipe.ACTION_MAX_CPU_TIME:
+		return action.MaxCPUTime(a, cmdEnv.cmd)
+	case recipe.ACTION_MAX_IO_READ:
+		return action.MaxIORead(a, cmdEnv.cmd)
+	case recipe.ACTION_MAX_IO_WRITE:
+		return action.MaxIOWrite(a, cmdEnv.cmd)
+	case recipe.ACTION_MAX_DURATION:
+		return action.MaxDuration(a, cmdEnv.cmd)
+	case recipe.ACTION_PROC_SNAPSHOT:
+		return action.ProcSnapshot(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_GROWTH:
+		return action.ProcGrowth(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_UID:
+		return action.ProcUID(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_GID:
+		return action.ProcGID(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_GROUP:
+		return action.ProcGroup(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_CAP:
+		return action.ProcCap(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_ENV:
+		return action.ProcEnv(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_CWD:
+		return action.ProcCwd(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_UMASK:
+		return action.ProcUmask(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_NICE:
+		return action.ProcNice(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_CGROUP:
+		return action.ProcCgroup(a, getCmd(cmdEnv))
+	case recipe.ACTION_PROC_EXE:
+		return action.ProcExe(a, getCmd(cmdEnv))
 	}
 
 	handler, ok := handlers[a.Name]
@@ -470,6 +839,15 @@ func runAction(a *recipe.Action, cmdEnv *CommandEnv) error {
 	return handler(a)
 }
 
+// getCmd returns command from command environment or nil for hollow commands
+func getCmd(cmdEnv *CommandEnv) *exec.Cmd {
+	if cmdEnv == nil {
+		return nil
+	}
+
+	return cmdEnv.cmd
+}
+
 // createPTY creates pseudo-terminal
 func createPTY(cmd *exec.Cmd) (*PTY, error) {
 	p, t, err := pty.Open()
@@ -498,13 +876,66 @@ func outputIOLoop(cmdEnv *CommandEnv) {
 			continue
 		}
 
-		if cmdEnv.cmd.ProcessState != nil && cmdEnv.cmd.ProcessState.Exited() {
+		// Process can be killed by signal, so we can't use ProcessState.Exited here
+		if cmdEnv.cmd.ProcessState != nil {
 			cmdEnv.term.Close()
 			return
 		}
 	}
 }
 
+// checkDeadline checks if command execution took longer than allowed
+func checkDeadline(c *recipe.Command) error {
+	deadline := c.GetDeadline()
+
+	if deadline <= 0 || c.Started.IsZero() {
+		return nil
+	}
+
+	dur := time.Since(c.Started)
+
+	if dur > timeutil.SecondsToDuration(deadline) {
+		return fmt.Errorf(
+			"Command deadline (%gs) exceeded (%s)",
+			deadline, timeutil.PrettyDuration(dur),
+		)
+	}
+
+	return nil
+}
+
+// finishCommand saves info about command process crash and errors found by
+// memory checkers. Memory checkers write complete reports only on process exit,
+// so command process with memory checkers is stopped if it is still running.
+func finishCommand(c *recipe.Command, cmdEnv *CommandEnv, err error) error {
+	if cmdEnv == nil {
+		return err
+	}
+
+	if cmdEnv.reports != "" {
+		stopCommand(cmdEnv)
+	}
+
+	checkCrash(c, cmdEnv)
+
+	return checkFindings(c, cmdEnv, err)
+}
+
+// collectResourceUsage saves info about resources used by command process if
+// process is finished
+func collectResourceUsage(c *recipe.Command, cmdEnv *CommandEnv) {
+	c.Usage = action.GetResourceUsage(c, cmdEnv.cmd)
+}
+
+// collectOutput saves the last lines of command output
+func collectOutput(e *Executor, c *recipe.Command, cmdEnv *CommandEnv) {
+	if cmdEnv == nil || cmdEnv.output.IsEmpty() {
+		return
+	}
+
+	c.Output = cmdEnv.output.Tail(e.config.DebugLines)
+}
+
 // skipCommand returns true if command should be skipped
 func skipCommand(c *recipe.Command, tags []string, lastSkippedGroupID uint8, finished bool) bool {
 	switch {
@@ -551,20 +982,34 @@ func logError(e *Executor, c *recipe.Command, a *recipe.Action, ce *CommandEnv,
 			e.logger.Info("(%s) Can't save output data: %v", origin, err)
 		}
 	}
+
+	if ce != nil && c.Crash != nil {
+		saveCrashReport(e, c, ce, recipeName, ts)
+	}
+
+	if len(c.Findings) != 0 {
+		saveFindingsReport(e, c, recipeName, ts)
+	}
 }
 
 // getErrorOrigin returns info about error origin
 func getErrorOrigin(c *recipe.Command, a *recipe.Action, ts int64) string {
+	cmdInfo := fmt.Sprintf("command: %d", c.Index()+1)
+
+	if c.IsHook() {
+		cmdInfo = "hook: " + c.Tag
+	}
+
 	switch a {
 	case nil:
 		return fmt.Sprintf(
-			"ts: %d | command: %d | line: %d",
-			ts, c.Index()+1, c.Line,
+			"ts: %d | %s | line: %d",
+			ts, cmdInfo, c.Line,
 		)
 	default:
 		return fmt.Sprintf(
-			"ts: %d | command: %d | action: %d:%s | line: %d",
-			ts, c.Index()+1, a.Index()+1, a.Name, a.Line,
+			"ts: %d | %s | action: %d:%s | line: %d",
+			ts, cmdInfo, a.Index()+1, a.Name, a.Line,
 		)
 	}
 }
@@ -601,6 +1046,19 @@ func getTempDir() (string, error) {
 	return tempDir, nil
 }
 
+// restoreBackups restores all objects from backups created by recipe
+func restoreBackups(e *Executor, r *recipe.Recipe) {
+	errs := action.RestoreBackups(r)
+
+	if e.config.Quiet {
+		return
+	}
+
+	for _, err := range errs {
+		fmtc.Fprintf(os.Stderr, "{r}%v{!}\n", err)
+	}
+}
+
 // cleanTempData removes temporary data
 func cleanTempData() {
 	if temp == nil {
@@ -0,0 +1,239 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+
+	"github.com/essentialkaos/bibop/action"
+	"github.com/essentialkaos/bibop/recipe"
+
+	. "github.com/essentialkaos/check"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+func Test(t *testing.T) { TestingT(t) }
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+type ExecutorSuite struct{}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+var _ = Suite(&ExecutorSuite{})
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+func (s *ExecutorSuite) TestRollback(c *C) {
+	wrkDir := c.MkDir()
+	extDir := c.MkDir()
+	errsDir := c.MkDir()
+
+	c.Assert(os.WriteFile(extDir+"/file", []byte("test"), 0640), IsNil)
+	c.Assert(os.WriteFile(extDir+"/meta", []byte("test"), 0600), IsNil)
+
+	r := recipe.NewRecipe(wrkDir + "/test.recipe")
+	r.Dir = wrkDir
+
+	cmd := recipe.NewCommand([]string{"echo"}, 1)
+	c.Assert(r.AddCommand(cmd, "", false), IsNil)
+
+	actions := []*recipe.Action{
+		{Name: recipe.ACTION_COPY, Arguments: []string{wrkDir + "/src", extDir + "/file"}},
+		{Name: recipe.ACTION_CHMOD, Arguments: []string{extDir + "/meta", "644"}},
+		{Name: recipe.ACTION_MKDIR, Arguments: []string{extDir + "/new"}},
+		{Name: recipe.ACTION_TOUCH, Arguments: []string{wrkDir + "/file"}},
+		{Name: recipe.ACTION_EXIST, Arguments: []string{extDir + "/file"}},
+	}
+
+	e := &Executor{
+		config:  &Config{ErrsDir: errsDir},
+		tracker: action.NewChangeTracker(c.MkDir()),
+	}
+
+	for _, a := range actions {
+		c.Assert(cmd.AddAction(a), IsNil)
+		c.Assert(trackChanges(e, a), IsNil)
+	}
+
+	c.Assert(os.WriteFile(extDir+"/file", []byte("changed"), 0640), IsNil)
+	c.Assert(os.Chmod(extDir+"/meta", 0644), IsNil)
+	c.Assert(os.Mkdir(extDir+"/new", 0755), IsNil)
+	c.Assert(os.WriteFile(wrkDir+"/file", nil, 0644), IsNil)
+
+	rollbackChanges(e, r)
+
+	data, err := os.ReadFile(extDir + "/file")
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "test")
+
+	info, err := os.Stat(extDir + "/meta")
+	c.Assert(err, IsNil)
+	c.Assert(info.Mode(), Equals, os.FileMode(0600))
+
+	_, err = os.Stat(extDir + "/new")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	_, err = os.Stat(wrkDir + "/file")
+	c.Assert(err, IsNil)
+
+	report, err := os.ReadFile(errsDir + "/test-rollback.log")
+	c.Assert(err, IsNil)
+	c.Assert(string(report), Matches, `(?s)Removed .*/new .*Restored mode and owner of .*/meta .*Restored .*/file\n`)
+}
+
+func (s *ExecutorSuite) TestIsOutsideWorkingDir(c *C) {
+	r := recipe.NewRecipe("/home/user/test.recipe")
+	r.Dir = "/home/user/test"
+
+	c.Assert(isOutsideWorkingDir(r, "/home/user/test"), Equals, false)
+	c.Assert(isOutsideWorkingDir(r, "/home/user/test/file"), Equals, false)
+	c.Assert(isOutsideWorkingDir(r, "/home/user/test1"), Equals, true)
+	c.Assert(isOutsideWorkingDir(r, "/home/user/test/../file"), Equals, true)
+	c.Assert(isOutsideWorkingDir(r, "/etc/passwd"), Equals, true)
+}
+
+func (s *ExecutorSuite) TestParseReports(c *C) {
+	type finding struct {
+		Summary  string
+		Location string
+	}
+
+	testCases := []struct {
+		File     string
+		Tool     string
+		Findings []finding
+	}{
+		{
+			"asan-overflow.log", recipe.WRAPPER_ASAN, []finding{
+				{"heap-buffer-overflow", "overflow (/tmp/san/bug.c:7)"},
+			},
+		},
+		{
+			"asan-leak.log", recipe.WRAPPER_ASAN, []finding{
+				{"64 byte(s) leaked in 1 allocation(s)", "leak (/tmp/san/bug.c:17)"},
+			},
+		},
+		{
+			"ubsan-shift.log", recipe.WRAPPER_UBSAN, []finding{
+				{"shift exponent 33 is too large for 32-bit type 'int'", "bug.c:13:12"},
+			},
+		},
+		{
+			"valgrind.xml", recipe.WRAPPER_VALGRIND, []finding{
+				{"Invalid read of size 4", "overflow (bug.c:7)"},
+				{"64 bytes in 1 blocks are definitely lost in loss record 1 of 1", "leak (bug.c:17)"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		data, err := os.ReadFile("../../testdata/reports/" + tc.File)
+		c.Assert(err, IsNil)
+
+		var findings []*recipe.Finding
+
+		if tc.Tool == recipe.WRAPPER_VALGRIND {
+			findings = parseValgrindReport(data)
+		} else {
+			findings = parseSanitizerReport(data, tc.Tool)
+		}
+
+		c.Assert(findings, HasLen, len(tc.Findings), Commentf("File: %s", tc.File))
+
+		for i, f := range findings {
+			c.Assert(f.Tool, Equals, tc.Tool)
+			c.Assert(finding{f.Summary, f.Location}, Equals, tc.Findings[i], Commentf("File: %s", tc.File))
+			c.Assert(f.Report, Not(Equals), "")
+		}
+	}
+
+	data, err := os.ReadFile("../../testdata/reports/asan-overflow.log")
+	c.Assert(err, IsNil)
+
+	findings := parseSanitizerReport(data, recipe.WRAPPER_ASAN)
+	c.Assert(findings[0].Report, Matches, `(?s)==\d+==ERROR: AddressSanitizer: .*==\d+==ABORTING`)
+
+	// Report of process killed in the middle of writing
+	data, err = os.ReadFile("../../testdata/reports/valgrind.xml")
+	c.Assert(err, IsNil)
+
+	findings = parseValgrindReport(data[:bytes.Index(data, []byte("<kind>Leak_"))])
+	c.Assert(findings, HasLen, 1)
+	c.Assert(findings[0].Report, Matches, `(?s)Invalid read of size 4\n   at 0x10918E: overflow \(bug.c:7\)\n.*Address 0x4a8c050 is 0 bytes after .*`)
+
+	c.Assert(parseValgrindReport([]byte("")), IsNil)
+	c.Assert(parseValgrindReport([]byte("<valgrindoutput><error>")), IsNil)
+	c.Assert(parseSanitizerReport([]byte("unknown data\n"), recipe.WRAPPER_ASAN), IsNil)
+}
+
+func (s *ExecutorSuite) TestCollectFindings(c *C) {
+	dir := c.MkDir()
+
+	for file, tool := range map[string]string{
+		"asan-leak.log":   recipe.WRAPPER_ASAN + ".1234",
+		"ubsan-shift.log": recipe.WRAPPER_UBSAN + ".1234",
+		"valgrind.xml":    recipe.WRAPPER_VALGRIND + ".1235",
+	} {
+		data, err := os.ReadFile("../../testdata/reports/" + file)
+		c.Assert(err, IsNil)
+		c.Assert(os.WriteFile(dir+"/report."+tool, data, 0600), IsNil)
+	}
+
+	c.Assert(collectFindings(dir+"/report"), HasLen, 4)
+	c.Assert(collectFindings(dir+"/unknown"), IsNil)
+}
+
+func (s *ExecutorSuite) TestRemoveCoverageData(c *C) {
+	dir := c.MkDir()
+
+	for _, file := range []string{
+		"covmeta.1a2b", "covcounters.1a2b.1234.5678", COVERAGE_PROFILE,
+		COVERAGE_SUMMARY, "coverage.html", "cover.sh", "notes.txt",
+	} {
+		c.Assert(os.WriteFile(dir+"/"+file, nil, 0644), IsNil)
+	}
+
+	c.Assert(os.Mkdir(dir+"/covmeta.dir", 0755), IsNil)
+
+	c.Assert(removeCoverageData(dir), IsNil)
+	c.Assert(removeCoverageData(dir+"/unknown"), NotNil)
+
+	files, err := os.ReadDir(dir)
+	c.Assert(err, IsNil)
+
+	var names []string
+
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+
+	c.Assert(names, DeepEquals, []string{"cover.sh", "coverage.html", "covmeta.dir", "notes.txt"})
+}
+
+func (s *ExecutorSuite) TestStartCommand(c *C) {
+	var output bytes.Buffer
+
+	cmd := exec.Command("/bin/sh", "-c", "ulimit -n; ulimit -t")
+	cmd.Stdout = &output
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+
+	c.Assert(startCommand(cmd, &recipe.Limits{NoFile: 64, CPUTime: 10}), IsNil)
+	c.Assert(cmd.Wait(), IsNil)
+	c.Assert(output.String(), Equals, "64\n10\n")
+
+	cmd = exec.Command("/unknown")
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+
+	c.Assert(startCommand(cmd, &recipe.Limits{NoFile: 64}), NotNil)
+}
@@ -0,0 +1,70 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/essentialkaos/bibop/recipe"
+	"github.com/essentialkaos/bibop/render"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// runHooks executes hook commands and returns false if any of them failed
+func runHooks(e *Executor, rr render.Renderer, hooks recipe.Commands) bool {
+	for _, hook := range hooks {
+		if isInterrupted(e) {
+			return false
+		}
+
+		hook.Started = time.Now()
+		rr.HookStarted(hook)
+
+		err := runHook(e, hook)
+
+		if err == nil {
+			rr.HookDone(hook)
+			continue
+		}
+
+		switch hook.Tag {
+		case recipe.SETUP_TAG:
+			err = fmt.Errorf("%v (all commands except teardown will be skipped)", err)
+		case recipe.BEFORE_EACH_TAG:
+			err = fmt.Errorf("%v (commands group will be skipped)", err)
+		}
+
+		e.hookFails++
+
+		rr.HookFailed(hook, err)
+
+		return false
+	}
+
+	return true
+}
+
+// runHook executes hook command and all its actions. Hook actions don't emit
+// renderer events, only hook itself does.
+func runHook(e *Executor, c *recipe.Command) error {
+	action, err := runCommandActions(e, &render.QuietRenderer{}, c)
+
+	if err != nil && action != nil {
+		actionName := action.Name
+
+		if action.Negative {
+			actionName = recipe.SYMBOL_NEGATIVE_ACTION + actionName
+		}
+
+		return fmt.Errorf("Action %q (line %d) failed: %v", actionName, action.Line, err)
+	}
+
+	return err
+}
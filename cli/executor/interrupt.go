@@ -0,0 +1,246 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/timeutil"
+
+	"github.com/essentialkaos/bibop/action"
+	"github.com/essentialkaos/bibop/recipe"
+	"github.com/essentialkaos/bibop/render"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// runRecipe processes recipe and handles interrupts and timeouts. On interrupt
+// current command will be aborted and only teardown commands will be executed.
+// Function always waits until recipe processing is finished, so renderer is
+// used only by one goroutine at a time.
+func runRecipe(e *Executor, rr render.Renderer, r *recipe.Recipe, tags []string, sigChan <-chan os.Signal) {
+	var timeout, teardownTimeout <-chan time.Time
+
+	done := make(chan bool)
+
+	if e.config.Timeout > 0 {
+		timer := time.NewTimer(timeutil.SecondsToDuration(e.config.Timeout))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	go func() {
+		processRecipe(e, rr, r, tags)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case sig := <-sigChan:
+		interruptRecipe(e, fmt.Sprintf(
+			"Recipe processing interrupted by %s signal", getSignalName(sig),
+		))
+	case <-timeout:
+		interruptRecipe(e, fmt.Sprintf(
+			"Recipe processing interrupted due to timeout (%s)",
+			timeutil.PrettyDuration(timeutil.SecondsToDuration(e.config.Timeout)),
+		))
+	}
+
+	if e.config.TeardownTimeout > 0 {
+		timer := time.NewTimer(timeutil.SecondsToDuration(e.config.TeardownTimeout))
+		defer timer.Stop()
+		teardownTimeout = timer.C
+	}
+
+	select {
+	case <-done:
+		return
+	case <-sigChan:
+		interruptRecipe(e, "teardown aborted by user")
+	case <-teardownTimeout:
+		interruptRecipe(e, fmt.Sprintf(
+			"teardown not finished in %s",
+			timeutil.PrettyDuration(timeutil.SecondsToDuration(e.config.TeardownTimeout)),
+		))
+	}
+
+	<-done
+}
+
+// interruptRecipe marks recipe processing as interrupted. The first call aborts
+// all commands except teardown commands, the second call aborts teardown
+// commands too.
+func interruptRecipe(e *Executor, reason string) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	if e.interruptReason == "" {
+		e.interruptReason = reason
+		close(e.interrupted)
+		return
+	}
+
+	e.interruptReason += "; " + reason
+
+	if !isClosed(e.aborted) {
+		close(e.aborted)
+	}
+}
+
+// watchCommand aborts command execution and kills command process group on
+// recipe interruption or if command deadline is exceeded. Returned function
+// stops watching and must be called after command execution.
+func watchCommand(e *Executor, c *recipe.Command, cmdEnv *CommandEnv) func() {
+	var timer *time.Timer
+	var deadline <-chan time.Time
+
+	stop, done := make(chan bool), make(chan bool)
+	abort := e.interrupted
+
+	if c.Tag == recipe.TEARDOWN_TAG {
+		abort = e.aborted
+	}
+
+	if !c.IsHook() && c.GetDeadline() > 0 {
+		timer = time.NewTimer(time.Until(
+			c.Started.Add(timeutil.SecondsToDuration(c.GetDeadline())),
+		))
+		deadline = timer.C
+	}
+
+	c.Aborted = make(chan bool)
+
+	go func() {
+		defer close(done)
+
+		if timer != nil {
+			defer timer.Stop()
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-abort:
+		case <-deadline:
+		}
+
+		close(c.Aborted)
+		killCommand(cmdEnv)
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// runInterruptibleAction runs action and returns reason of abort as an error if
+// command execution was aborted while action was running
+func runInterruptibleAction(e *Executor, a *recipe.Action, cmdEnv *CommandEnv) error {
+	err := runAction(e, a, cmdEnv)
+	abortErr := getAbortError(e, a.Command)
+
+	if abortErr != nil {
+		return abortErr
+	}
+
+	return err
+}
+
+// getAbortError returns error with reason of command execution abort or nil if
+// command execution wasn't aborted
+func getAbortError(e *Executor, c *recipe.Command) error {
+	switch {
+	case !isClosed(c.Aborted):
+		return nil
+	case c.Tag == recipe.TEARDOWN_TAG && isTeardownAborted(e),
+		c.Tag != recipe.TEARDOWN_TAG && isInterrupted(e):
+		return fmt.Errorf("%s", getInterruptReason(e))
+	}
+
+	err := checkDeadline(c)
+
+	if err != nil {
+		return err
+	}
+
+	return action.ErrAborted
+}
+
+// getInterruptReason returns reason of recipe processing interruption
+func getInterruptReason(e *Executor) string {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	return e.interruptReason
+}
+
+// isInterrupted returns true if recipe processing was interrupted
+func isInterrupted(e *Executor) bool {
+	return isClosed(e.interrupted)
+}
+
+// isTeardownAborted returns true if execution of teardown commands was aborted
+func isTeardownAborted(e *Executor) bool {
+	return isClosed(e.aborted)
+}
+
+// isClosed returns true if given channel is closed
+func isClosed(ch chan bool) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// killCommand kills command process group
+func killCommand(cmdEnv *CommandEnv) {
+	if cmdEnv == nil || cmdEnv.cmd == nil || cmdEnv.cmd.Process == nil {
+		return
+	}
+
+	// Every command is started in its own session, so PGID is equal to PID
+	syscall.Kill(-cmdEnv.cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// stopCommand terminates command process group and waits until the process is
+// finished
+func stopCommand(cmdEnv *CommandEnv) {
+	if isClosed(cmdEnv.exited) {
+		return
+	}
+
+	syscall.Kill(-cmdEnv.cmd.Process.Pid, syscall.SIGTERM)
+
+	// Memory checkers can spend some time on leak checking after termination
+	select {
+	case <-cmdEnv.exited:
+	case <-time.After(STOP_TIMEOUT):
+		killCommand(cmdEnv)
+		<-cmdEnv.exited
+	}
+}
+
+// getSignalName returns name of signal
+func getSignalName(sig os.Signal) string {
+	switch sig {
+	case syscall.SIGINT:
+		return "SIGINT"
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	}
+
+	return sig.String()
+}
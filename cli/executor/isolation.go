@@ -0,0 +1,273 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// isolatedDirs is a list of directories covered by copy-on-write overlays in
+// private mount namespace
+var isolatedDirs = []string{"/etc", "/home", "/opt", "/root", "/srv", "/usr", "/var"}
+
+// privateDirs is a list of directories replaced by empty tmpfs in private mount
+// namespace
+var privateDirs = []string{"/tmp"}
+
+// procMountScript is script used for mounting procfs inside PID namespace
+const procMountScript = `mount -t proc proc /proc && exec "$@"`
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// startIsolatedCommand starts command in private namespaces. Shared directories
+// stay available for command in private mount namespace.
+func startIsolatedCommand(cmd *exec.Cmd, isolation *recipe.Isolation, limits *recipe.Limits, sharedDirs []string) error {
+	errChan := make(chan error, 1)
+
+	if isolation.PID {
+		addProcMountWrapper(cmd)
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWPID
+	}
+
+	go func() {
+		// We never unlock thread, so it will be destroyed with all namespaces
+		// after the goroutine exit
+		runtime.LockOSThread()
+		errChan <- setupNamespaces(cmd, isolation, limits, sharedDirs)
+	}()
+
+	return <-errChan
+}
+
+// setupNamespaces moves current thread to new namespaces and starts command
+func setupNamespaces(cmd *exec.Cmd, isolation *recipe.Isolation, limits *recipe.Limits, sharedDirs []string) error {
+	var flags int
+
+	if isolation.Mount || isolation.PID {
+		flags |= syscall.CLONE_NEWNS
+	}
+
+	if isolation.Network {
+		flags |= syscall.CLONE_NEWNET
+	}
+
+	err := syscall.Unshare(flags)
+
+	if err != nil {
+		return fmt.Errorf("Can't create namespaces: %v", err)
+	}
+
+	if flags&syscall.CLONE_NEWNS != 0 {
+		err = syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, "")
+
+		if err != nil {
+			return fmt.Errorf("Can't make mounts private: %v", err)
+		}
+	}
+
+	if isolation.Mount {
+		err = isolateFilesystem(sharedDirs)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if isolation.Network {
+		err = enableLoopback()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return startCommand(cmd, limits)
+}
+
+// isolateFilesystem covers system directories by copy-on-write overlays. Working
+// directory and shared directories stay writable.
+func isolateFilesystem(sharedDirs []string) error {
+	tmpDir, err := getTempDir()
+
+	if err != nil {
+		return err
+	}
+
+	workingDir, err := os.Getwd()
+
+	if err != nil {
+		return fmt.Errorf("Can't get current working directory: %v", err)
+	}
+
+	// Directories must be opened before mounting, because they may be hidden
+	// by tmpfs or overlays
+	keptDirs := map[string]int{}
+
+	for _, dir := range append([]string{workingDir}, sharedDirs...) {
+		if !isCoveredDir(dir) {
+			continue
+		}
+
+		fd, err := syscall.Open(dir, syscall.O_RDONLY|syscall.O_DIRECTORY, 0)
+
+		if err != nil {
+			return fmt.Errorf("Can't open directory %s: %v", dir, err)
+		}
+
+		defer syscall.Close(fd)
+
+		keptDirs[dir] = fd
+	}
+
+	stagingDir, err := os.MkdirTemp(tmpDir, "isolation")
+
+	if err != nil {
+		return fmt.Errorf("Can't create directory for isolation data: %v", err)
+	}
+
+	err = syscall.Mount("tmpfs", stagingDir, "tmpfs", 0, "mode=0700")
+
+	if err != nil {
+		return fmt.Errorf("Can't mount tmpfs to %s: %v", stagingDir, err)
+	}
+
+	for _, dir := range isolatedDirs {
+		if !isRealDir(dir) {
+			continue
+		}
+
+		err = mountOverlay(dir, stagingDir+dir)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range privateDirs {
+		if !isRealDir(dir) {
+			continue
+		}
+
+		err = syscall.Mount("tmpfs", dir, "tmpfs", 0, "mode=1777")
+
+		if err != nil {
+			return fmt.Errorf("Can't mount tmpfs to %s: %v", dir, err)
+		}
+	}
+
+	for dir, fd := range keptDirs {
+		// Directory may be hidden by tmpfs, so we have to create mount point
+		err = os.MkdirAll(dir, 0700)
+
+		if err != nil {
+			return fmt.Errorf("Can't mount directory %s: %v", dir, err)
+		}
+
+		err = syscall.Mount(
+			fmt.Sprintf("/proc/self/fd/%d", fd),
+			dir, "", syscall.MS_BIND|syscall.MS_REC, "",
+		)
+
+		if err != nil {
+			return fmt.Errorf("Can't mount directory %s: %v", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// mountOverlay mounts overlay with upper layer in given directory over target
+// directory
+func mountOverlay(target, dataDir string) error {
+	upperDir, workDir := dataDir+"/upper", dataDir+"/work"
+
+	for _, dir := range []string{upperDir, workDir} {
+		err := os.MkdirAll(dir, 0700)
+
+		if err != nil {
+			return fmt.Errorf("Can't create directory for overlay data: %v", err)
+		}
+	}
+
+	err := syscall.Mount(
+		"overlay", target, "overlay", 0,
+		fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", target, upperDir, workDir),
+	)
+
+	if err != nil {
+		return fmt.Errorf("Can't mount overlay to %s: %v", target, err)
+	}
+
+	return nil
+}
+
+// enableLoopback brings up loopback interface in current network namespace
+func enableLoopback() error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+
+	if err != nil {
+		return fmt.Errorf("Can't configure loopback interface: %v", err)
+	}
+
+	defer syscall.Close(fd)
+
+	// struct ifreq with interface name and flags
+	var req [40]byte
+
+	copy(req[:], "lo")
+	*(*uint16)(unsafe.Pointer(&req[syscall.IFNAMSIZ])) = syscall.IFF_UP | syscall.IFF_LOOPBACK | syscall.IFF_RUNNING
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL, uintptr(fd),
+		syscall.SIOCSIFFLAGS, uintptr(unsafe.Pointer(&req[0])),
+	)
+
+	if errno != 0 {
+		return fmt.Errorf("Can't configure loopback interface: %v", errno)
+	}
+
+	return nil
+}
+
+// addProcMountWrapper wraps command with script which mounts procfs for
+// new PID namespace
+func addProcMountWrapper(cmd *exec.Cmd) {
+	args := append([]string{"/bin/sh", "-c", procMountScript, "sh", cmd.Path}, cmd.Args[1:]...)
+
+	cmd.Path, cmd.Args = "/bin/sh", args
+}
+
+// isRealDir returns true if given path is directory and not a link
+func isRealDir(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.IsDir()
+}
+
+// isCoveredDir returns true if directory is covered by overlay or tmpfs
+func isCoveredDir(path string) bool {
+	path = filepath.Clean(path)
+
+	for _, dir := range append(isolatedDirs, privateDirs...) {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
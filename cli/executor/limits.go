@@ -0,0 +1,372 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// CGROUP_ROOT is path to cgroup v2 hierarchy
+const CGROUP_ROOT = "/sys/fs/cgroup"
+
+// cpuPeriod is CPU period used for cgroup CPU limit (in microseconds)
+const cpuPeriod = 100000
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// cgroups contains paths to all cgroups created for commands
+var cgroups []string
+
+// cgroupParent is path to bibop cgroup used as a parent for command cgroups
+var cgroupParent string
+
+// cgroupLeaf is path to cgroup created for bibop process itself
+var cgroupLeaf string
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// createCgroup creates cgroup with given limits and configures command for
+// starting in it. It returns path to created cgroup and its descriptor, which
+// must be closed after command start.
+func createCgroup(cmd *exec.Cmd, limits *recipe.Limits) (string, int, error) {
+	parentDir, err := getCgroupParentDir()
+
+	if err != nil {
+		return "", -1, err
+	}
+
+	err = enableCgroupControllers(parentDir, limits)
+
+	if err != nil {
+		return "", -1, err
+	}
+
+	cgroupDir := filepath.Join(
+		parentDir, fmt.Sprintf("bibop-%d-%d", os.Getpid(), len(cgroups)),
+	)
+
+	err = os.Mkdir(cgroupDir, 0755)
+
+	if err != nil {
+		return "", -1, fmt.Errorf("Can't create cgroup: %v", err)
+	}
+
+	cgroups = append(cgroups, cgroupDir)
+
+	if limits.Memory != 0 {
+		err = writeCgroupFile(cgroupDir, "memory.max", fmt.Sprintf("%d", limits.Memory))
+
+		if err != nil {
+			return "", -1, err
+		}
+
+		// Process shouldn't bypass memory limit using swap
+		writeCgroupFile(cgroupDir, "memory.swap.max", "0")
+	}
+
+	if limits.CPU != 0 {
+		quota := int64(limits.CPU / 100.0 * cpuPeriod)
+		err = writeCgroupFile(cgroupDir, "cpu.max", fmt.Sprintf("%d %d", max(quota, 1000), cpuPeriod))
+
+		if err != nil {
+			return "", -1, err
+		}
+	}
+
+	if limits.PIDs != 0 {
+		err = writeCgroupFile(cgroupDir, "pids.max", fmt.Sprintf("%d", limits.PIDs))
+
+		if err != nil {
+			return "", -1, err
+		}
+	}
+
+	fd, err := syscall.Open(cgroupDir, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+
+	if err != nil {
+		return "", -1, fmt.Errorf("Can't open cgroup: %v", err)
+	}
+
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = fd
+
+	return cgroupDir, fd, nil
+}
+
+// startCommand starts command and applies rlimits to the command process. To
+// apply limits before the command executes any code, process is started under
+// ptrace, so it stops right after exec. Limits are applied to stopped process
+// and then process is detached.
+func startCommand(cmd *exec.Cmd, limits *recipe.Limits) error {
+	if !limits.HasRLimits() {
+		return cmd.Start()
+	}
+
+	// Tracer is the thread which started the process, so all ptrace requests
+	// must be made from the same thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	cmd.SysProcAttr.Ptrace = true
+
+	err := cmd.Start()
+
+	if err != nil {
+		return err
+	}
+
+	pid := cmd.Process.Pid
+
+	var status syscall.WaitStatus
+
+	_, err = syscall.Wait4(pid, &status, 0, nil)
+
+	if err == nil && !status.Stopped() {
+		err = fmt.Errorf("process is not stopped after exec")
+	}
+
+	if err == nil {
+		err = setRLimits(pid, limits)
+		syscall.PtraceDetach(pid)
+	}
+
+	if err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("Can't set resource limits: %v", err)
+	}
+
+	return nil
+}
+
+// setRLimits sets resource limits for process with given PID
+func setRLimits(pid int, limits *recipe.Limits) error {
+	for _, rl := range []struct {
+		resource int
+		value    uint64
+	}{
+		{unix.RLIMIT_NOFILE, limits.NoFile},
+		{unix.RLIMIT_CPU, limits.CPUTime},
+		{unix.RLIMIT_AS, limits.AddressSpace},
+	} {
+		if rl.value == 0 {
+			continue
+		}
+
+		err := unix.Prlimit(pid, rl.resource, &unix.Rlimit{Cur: rl.value, Max: rl.value}, nil)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeCgroups kills all processes in cgroups created for commands and removes
+// these cgroups
+func removeCgroups() {
+	for _, cgroupDir := range cgroups {
+		writeCgroupFile(cgroupDir, "cgroup.kill", "1")
+
+		// Killing processes is asynchronous, so we have to wait a bit
+		for range 20 {
+			if syscall.Rmdir(cgroupDir) != syscall.EBUSY {
+				break
+			}
+
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	cgroups = nil
+
+	if cgroupLeaf == "" {
+		return
+	}
+
+	// Controllers must be disabled before moving bibop process back to its
+	// original cgroup due to "no internal processes" rule
+	for _, controller := range []string{"memory", "cpu", "pids", "io"} {
+		writeCgroupFile(cgroupParent, "cgroup.subtree_control", "-"+controller)
+	}
+
+	if writeCgroupFile(cgroupParent, "cgroup.procs", strconv.Itoa(os.Getpid())) == nil {
+		syscall.Rmdir(cgroupLeaf)
+	}
+
+	cgroupParent, cgroupLeaf = "", ""
+}
+
+// getCgroupParentDir returns path to cgroup which will be used as a parent for
+// command cgroups. Command cgroups are created in the current bibop cgroup,
+// which must be delegated to the user running bibop. Due to "no internal
+// processes" rule, bibop process is moved to the leaf child cgroup.
+func getCgroupParentDir() (string, error) {
+	if cgroupParent != "" {
+		return cgroupParent, nil
+	}
+
+	_, err := os.Stat(CGROUP_ROOT + "/cgroup.controllers")
+
+	if err != nil {
+		return "", fmt.Errorf("Limits for memory, CPU and processes require cgroup v2")
+	}
+
+	cgroupDir, err := getCurrentCgroupDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	// Root cgroup is not affected by "no internal processes" rule
+	if cgroupDir == CGROUP_ROOT {
+		cgroupParent = cgroupDir
+		return cgroupParent, nil
+	}
+
+	err = checkCgroupProcesses(cgroupDir)
+
+	if err != nil {
+		return "", err
+	}
+
+	leafDir := filepath.Join(cgroupDir, fmt.Sprintf("bibop-%d", os.Getpid()))
+	err = os.Mkdir(leafDir, 0755)
+
+	if err != nil {
+		return "", fmt.Errorf("Can't create cgroup: %v", err)
+	}
+
+	err = writeCgroupFile(leafDir, "cgroup.procs", strconv.Itoa(os.Getpid()))
+
+	if err != nil {
+		syscall.Rmdir(leafDir)
+		return "", err
+	}
+
+	cgroupParent, cgroupLeaf = cgroupDir, leafDir
+
+	return cgroupParent, nil
+}
+
+// getCurrentCgroupDir returns path to cgroup v2 of current process
+func getCurrentCgroupDir() (string, error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+
+	if err != nil {
+		return "", fmt.Errorf("Can't read current cgroup info: %v", err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			return filepath.Join(CGROUP_ROOT, strings.TrimPrefix(line, "0::")), nil
+		}
+	}
+
+	return "", fmt.Errorf("Can't find current cgroup v2 info")
+}
+
+// checkCgroupProcesses checks that cgroup contains only bibop process, so it
+// can be used as a parent for command cgroups
+func checkCgroupProcesses(cgroupDir string) error {
+	data, err := os.ReadFile(cgroupDir + "/cgroup.procs")
+
+	if err != nil {
+		return fmt.Errorf("Can't read cgroup processes info: %v", err)
+	}
+
+	for _, pid := range strings.Fields(string(data)) {
+		if pid != strconv.Itoa(os.Getpid()) {
+			return fmt.Errorf(
+				"Limits for memory, CPU and processes require bibop to be started in its own delegated cgroup (e.g. using \"systemd-run --scope -p Delegate=yes\")",
+			)
+		}
+	}
+
+	return nil
+}
+
+// enableCgroupControllers enables controllers required for limits
+func enableCgroupControllers(cgroupDir string, limits *recipe.Limits) error {
+	var controllers []string
+
+	if limits.Memory != 0 {
+		controllers = append(controllers, "memory")
+	}
+
+	if limits.CPU != 0 {
+		controllers = append(controllers, "cpu")
+	}
+
+	if limits.PIDs != 0 {
+		controllers = append(controllers, "pids")
+	}
+
+	data, err := os.ReadFile(cgroupDir + "/cgroup.controllers")
+
+	if err != nil {
+		return fmt.Errorf("Can't read cgroup controllers info: %v", err)
+	}
+
+	// Memory and I/O controllers provide stats about used resources, so they are
+	// enabled if available even if there are no limits for them
+	for _, controller := range []string{"memory", "io"} {
+		if !slices.Contains(controllers, controller) &&
+			slices.Contains(strings.Fields(string(data)), controller) {
+			controllers = append(controllers, controller)
+		}
+	}
+
+	data, err = os.ReadFile(cgroupDir + "/cgroup.subtree_control")
+
+	if err != nil {
+		return fmt.Errorf("Can't read cgroup controllers info: %v", err)
+	}
+
+	enabled := strings.Fields(string(data))
+
+	for _, controller := range controllers {
+		if slices.Contains(enabled, controller) {
+			continue
+		}
+
+		err = writeCgroupFile(cgroupDir, "cgroup.subtree_control", "+"+controller)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCgroupFile writes data to cgroup interface file
+func writeCgroupFile(cgroupDir, file, data string) error {
+	err := os.WriteFile(filepath.Join(cgroupDir, file), []byte(data), 0644)
+
+	if err != nil {
+		return fmt.Errorf("Can't configure cgroup (%s): %v", file, err)
+	}
+
+	return nil
+}
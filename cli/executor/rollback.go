@@ -0,0 +1,139 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/essentialkaos/ek/v13/fmtc"
+	"github.com/essentialkaos/ek/v13/strutil"
+
+	"github.com/essentialkaos/bibop/action"
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// setupChangeTracker creates tracker for filesystem changes made by actions
+func setupChangeTracker(e *Executor) error {
+	tmpDir, err := getTempDir()
+
+	if err != nil {
+		return err
+	}
+
+	trackerDir := tmpDir + "/rollback"
+	err = os.Mkdir(trackerDir, 0700)
+
+	if err != nil {
+		return fmt.Errorf("Can't create directory for rollback data: %v", err)
+	}
+
+	e.tracker = action.NewChangeTracker(trackerDir)
+
+	return nil
+}
+
+// trackChanges saves original state of objects outside of working dir which
+// will be modified by action
+func trackChanges(e *Executor, a *recipe.Action) error {
+	var targets []string
+	var withContent bool
+
+	switch a.Name {
+	case recipe.ACTION_COPY, recipe.ACTION_TEMPLATE:
+		targets, withContent = getActionArgs(a, 1), true
+	case recipe.ACTION_MOVE:
+		targets, withContent = getActionArgs(a, 0, 1), true
+	case recipe.ACTION_TOUCH, recipe.ACTION_MKDIR, recipe.ACTION_REMOVE,
+		recipe.ACTION_TRUNCATE, recipe.ACTION_CLEANUP, recipe.ACTION_BACKUP_RESTORE:
+		targets, withContent = getActionArgs(a, 0), true
+	case recipe.ACTION_CHMOD, recipe.ACTION_CHOWN, recipe.ACTION_FILE_CAP_SET,
+		recipe.ACTION_XATTR_SET, recipe.ACTION_SELINUX_CONTEXT_SET,
+		recipe.ACTION_FILE_ATTR_SET:
+		targets = getActionArgs(a, 0)
+	}
+
+	for _, target := range targets {
+		if !isOutsideWorkingDir(a.Command.Recipe, target) {
+			continue
+		}
+
+		err := e.tracker.Track(target, withContent)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackChanges restores original state of all modified objects and saves
+// report with info about restored objects
+func rollbackChanges(e *Executor, r *recipe.Recipe) {
+	report := e.tracker.Restore()
+
+	if len(report) == 0 {
+		return
+	}
+
+	if e.config.ErrsDir == "" {
+		if !e.config.Quiet {
+			for _, info := range report {
+				fmtc.Fprintf(os.Stderr, "{y}Rollback:{!} %s\n", info)
+			}
+		}
+
+		return
+	}
+
+	recipeName := strutil.Exclude(filepath.Base(r.File), ".recipe")
+	reportFile := fmt.Sprintf("%s/%s-rollback.log", e.config.ErrsDir, recipeName)
+
+	err := os.WriteFile(reportFile, []byte(strings.Join(report, "\n")+"\n"), 0644)
+
+	if err != nil {
+		fmtc.Fprintf(os.Stderr, "{r}Can't save rollback report: %v{!}\n", err)
+	}
+}
+
+// getActionArgs returns action arguments with given indexes
+func getActionArgs(a *recipe.Action, indexes ...int) []string {
+	var result []string
+
+	for _, index := range indexes {
+		arg, err := a.GetS(index)
+
+		if err == nil {
+			result = append(result, arg)
+		}
+	}
+
+	return result
+}
+
+// isOutsideWorkingDir returns true if given path is outside of working dir
+func isOutsideWorkingDir(r *recipe.Recipe, path string) bool {
+	targetPath, err := filepath.Abs(path)
+
+	if err != nil {
+		return true
+	}
+
+	workingDir, err := filepath.Abs(r.Dir)
+
+	if err != nil {
+		return true
+	}
+
+	return targetPath != workingDir && !strings.HasPrefix(targetPath, workingDir+"/")
+}
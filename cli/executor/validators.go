@@ -90,7 +90,7 @@ func checkRecipeVariables(r *recipe.Recipe) []error {
 	knownVars := append(recipe.DynamicVariables[:0:0], recipe.DynamicVariables...)
 	varRegex := regexp.MustCompile(`\{([a-zA-Z0-9_-]+)\}`)
 
-	for _, c := range r.Commands {
+	for _, c := range append(r.Hooks(), r.Commands...) {
 		submatch := varRegex.FindAllStringSubmatch(c.GetCmdline(), -1)
 
 		if len(submatch) != 0 {
@@ -143,7 +143,7 @@ func checkDependencies(r *recipe.Recipe) []error {
 
 	binCache := make(map[string]bool)
 
-	for _, c := range r.Commands {
+	for _, c := range append(r.Hooks(), r.Commands...) {
 		for _, a := range c.Actions {
 			var binary string
 
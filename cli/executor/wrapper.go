@@ -0,0 +1,403 @@
+package executor
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/essentialkaos/ek/v13/pluralize"
+	"github.com/essentialkaos/ek/v13/system"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// valgrindError contains info about error from valgrind XML report
+type valgrindError struct {
+	Kind    string          `xml:"kind"`
+	What    string          `xml:"what"`
+	XWhat   string          `xml:"xwhat>text"`
+	AuxWhat []string        `xml:"auxwhat"`
+	Stacks  []valgrindStack `xml:"stack"`
+}
+
+// valgrindStack contains stack trace from valgrind XML report
+type valgrindStack struct {
+	Frames []valgrindFrame `xml:"frame"`
+}
+
+// valgrindFrame contains info about stack frame from valgrind XML report
+type valgrindFrame struct {
+	IP   string `xml:"ip"`
+	Obj  string `xml:"obj"`
+	Fn   string `xml:"fn"`
+	File string `xml:"file"`
+	Line int    `xml:"line"`
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+var (
+	sanitizerErrorRegex  = regexp.MustCompile(`^==\d+==ERROR: \w+Sanitizer: (.+)$`)
+	sanitizerAddrRegex   = regexp.MustCompile(`\s+on\s+(unknown address|address|0x).*$`)
+	sanitizerSumRegex    = regexp.MustCompile(`^SUMMARY: \w+Sanitizer: (.+)$`)
+	sanitizerFrameRegex  = regexp.MustCompile(`^\s+#\d+ 0x[0-9a-f]+ in (\S+) (.+)$`)
+	sanitizerRTErrRegex  = regexp.MustCompile(`^(.+:\d+(?::\d+)?): runtime error: (.+)$`)
+	sanitizerAbortRegex  = regexp.MustCompile(`^==\d+==ABORTING`)
+	sanitizerRuntimeLibs = []string{
+		"libasan", "libubsan", "liblsan", "libsanitizer", "libclang_rt", "compiler-rt",
+	}
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// getReportsPrefix returns path prefix for memory checkers reports
+func getReportsPrefix(c *recipe.Command) (string, error) {
+	_, err := getTempDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	// Reports are written by command process, so if command is executed by other
+	// user, this user must own the directory. Directory with other temporary data
+	// is accessible only by current user, so reports are saved to a separate
+	// temporary directory next to it.
+	dir, err := temp.MkDir("reports")
+
+	if err == nil {
+		err = os.Chmod(dir, 0700)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("Can't create directory for memory checker reports: %v", err)
+	}
+
+	if c.User != "" {
+		user, err := system.LookupUser(c.User)
+
+		if err == nil {
+			err = os.Chown(dir, user.UID, user.GID)
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("Can't set owner of memory checker reports directory: %v", err)
+		}
+	}
+
+	return dir + "/report", nil
+}
+
+// getWrapperArgs returns arguments for command wrapper
+func getWrapperArgs(w *recipe.Wrapper, reports string) []string {
+	if !w.Valgrind {
+		return nil
+	}
+
+	return []string{
+		"valgrind", "--tool=memcheck", "--leak-check=full",
+		"--show-leak-kinds=definite", "--errors-for-leak-kinds=definite",
+		"--xml=yes", "--xml-file=" + reports + ".valgrind.%p",
+		"--log-file=/dev/null",
+	}
+}
+
+// getWrapperEnv returns environment variables for sanitizers
+func getWrapperEnv(c *recipe.Command, w *recipe.Wrapper, reports string) []string {
+	var result []string
+
+	if w.ASan {
+		result = append(result, getSanitizerOptions(
+			c, "ASAN_OPTIONS", "detect_leaks=1:log_path="+reports+".asan",
+		))
+	}
+
+	if w.UBSan {
+		result = append(result, getSanitizerOptions(
+			c, "UBSAN_OPTIONS", "print_stacktrace=1:log_path="+reports+".ubsan",
+		))
+	}
+
+	return result
+}
+
+// getSanitizerOptions appends given options to sanitizer options defined in
+// environment or in command
+func getSanitizerOptions(c *recipe.Command, name, options string) string {
+	value := os.Getenv(name)
+
+	for _, env := range c.Env {
+		if strings.HasPrefix(env, name+"=") {
+			value = strings.TrimPrefix(env, name+"=")
+		}
+	}
+
+	if value == "" {
+		return name + "=" + options
+	}
+
+	return name + "=" + value + ":" + options
+}
+
+// checkFindings collects errors found by memory checkers and returns error if
+// there are some errors
+func checkFindings(c *recipe.Command, cmdEnv *CommandEnv, err error) error {
+	if cmdEnv == nil || cmdEnv.reports == "" {
+		return err
+	}
+
+	c.Findings = collectFindings(cmdEnv.reports)
+
+	if err != nil || len(c.Findings) == 0 {
+		return err
+	}
+
+	return fmt.Errorf(
+		"Memory checker found %s",
+		pluralize.P("%d %s", len(c.Findings), "error", "errors"),
+	)
+}
+
+// collectFindings parses all reports with given prefix
+func collectFindings(reports string) []*recipe.Finding {
+	var result []*recipe.Finding
+
+	files, _ := filepath.Glob(reports + ".*")
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+
+		if err != nil {
+			continue
+		}
+
+		tool, _, _ := strings.Cut(strings.TrimPrefix(file, reports+"."), ".")
+
+		switch tool {
+		case recipe.WRAPPER_VALGRIND:
+			result = append(result, parseValgrindReport(data)...)
+		case recipe.WRAPPER_ASAN, recipe.WRAPPER_UBSAN:
+			result = append(result, parseSanitizerReport(data, tool)...)
+		}
+	}
+
+	return result
+}
+
+// saveFindingsReport saves full reports of memory checkers into errors directory
+func saveFindingsReport(e *Executor, c *recipe.Command, recipeName string, ts int64) {
+	var report bytes.Buffer
+
+	fmt.Fprintf(&report, "Command: %s\n", c.GetCmdline())
+
+	for _, f := range c.Findings {
+		fmt.Fprintf(&report, "\n[%s] %s\n", f.Tool, f.Summary)
+
+		if f.Location != "" {
+			fmt.Fprintf(&report, "Location: %s\n", f.Location)
+		}
+
+		fmt.Fprintf(&report, "\n%s\n", f.Report)
+	}
+
+	reportFile := fmt.Sprintf("%s/%s-findings-%d.log", e.config.ErrsDir, recipeName, ts)
+	err := os.WriteFile(reportFile, report.Bytes(), 0644)
+
+	if err != nil {
+		e.logger.Info("Can't save memory checker report: %v", err)
+	}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// parseValgrindReport parses valgrind XML report
+func parseValgrindReport(data []byte) []*recipe.Finding {
+	var result []*recipe.Finding
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		// Report can be incomplete if process is still running, so we just use
+		// all errors which we can read
+		token, err := decoder.Token()
+
+		if err != nil {
+			break
+		}
+
+		elem, ok := token.(xml.StartElement)
+
+		if !ok || elem.Name.Local != "error" {
+			continue
+		}
+
+		vErr := &valgrindError{}
+
+		if decoder.DecodeElement(vErr, &elem) != nil {
+			break
+		}
+
+		result = append(result, vErr.ToFinding())
+	}
+
+	return result
+}
+
+// parseSanitizerReport parses ASan or UBSan text report
+func parseSanitizerReport(data []byte, tool string) []*recipe.Finding {
+	var result []*recipe.Finding
+	var cur *recipe.Finding
+	var report []string
+
+	flush := func() {
+		if cur != nil {
+			cur.Report = strings.TrimSpace(strings.Join(report, "\n"))
+			result = append(result, cur)
+		}
+
+		cur, report = nil, nil
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if m := sanitizerErrorRegex.FindStringSubmatch(line); m != nil {
+			flush()
+			cur = &recipe.Finding{
+				Tool:    tool,
+				Summary: sanitizerAddrRegex.ReplaceAllString(m[1], ""),
+			}
+		} else if m := sanitizerRTErrRegex.FindStringSubmatch(line); m != nil {
+			flush()
+			cur = &recipe.Finding{Tool: tool, Summary: m[2], Location: m[1]}
+		}
+
+		if cur == nil {
+			continue
+		}
+
+		report = append(report, line)
+
+		switch {
+		case sanitizerAbortRegex.MatchString(line):
+			flush()
+		case strings.HasPrefix(line, "SUMMARY: "):
+			m := sanitizerSumRegex.FindStringSubmatch(line)
+
+			// Use summary with number of leaked bytes instead of generic message
+			if m != nil && strings.Contains(m[1], " leaked in ") {
+				cur.Summary = strings.TrimSuffix(m[1], ".")
+			}
+		case cur.Location == "":
+			m := sanitizerFrameRegex.FindStringSubmatch(line)
+
+			if m != nil && !isSanitizerRuntimeFrame(m[2]) {
+				cur.Location = fmt.Sprintf("%s (%s)", m[1], m[2])
+			}
+		}
+	}
+
+	flush()
+
+	return result
+}
+
+// isSanitizerRuntimeFrame returns true if given frame location points to
+// sanitizer runtime library
+func isSanitizerRuntimeFrame(location string) bool {
+	for _, lib := range sanitizerRuntimeLibs {
+		if strings.Contains(location, lib) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// ToFinding converts valgrind error to finding
+func (e *valgrindError) ToFinding() *recipe.Finding {
+	var report strings.Builder
+
+	summary := e.What
+
+	if summary == "" {
+		summary = e.XWhat
+	}
+
+	if summary == "" {
+		summary = e.Kind
+	}
+
+	report.WriteString(summary + "\n")
+
+	for i, stack := range e.Stacks {
+		if i > 0 && i-1 < len(e.AuxWhat) {
+			report.WriteString(" " + e.AuxWhat[i-1] + "\n")
+		}
+
+		for j, frame := range stack.Frames {
+			prefix := "by"
+
+			if j == 0 {
+				prefix = "at"
+			}
+
+			fmt.Fprintf(&report, "   %s %s: %s\n", prefix, frame.IP, frame.String())
+		}
+	}
+
+	return &recipe.Finding{
+		Tool:     recipe.WRAPPER_VALGRIND,
+		Summary:  summary,
+		Location: e.getLocation(),
+		Report:   strings.TrimSpace(report.String()),
+	}
+}
+
+// getLocation returns the first frame from user code
+func (e *valgrindError) getLocation() string {
+	if len(e.Stacks) == 0 || len(e.Stacks[0].Frames) == 0 {
+		return ""
+	}
+
+	for _, frame := range e.Stacks[0].Frames {
+		// Skip valgrind replacements for malloc/free
+		if frame.File != "" && !strings.Contains(frame.Obj, "vgpreload") {
+			return frame.String()
+		}
+	}
+
+	return e.Stacks[0].Frames[0].String()
+}
+
+// String returns string representation of frame
+func (f valgrindFrame) String() string {
+	fn := f.Fn
+
+	if fn == "" {
+		fn = "???"
+	}
+
+	switch {
+	case f.File != "":
+		return fmt.Sprintf("%s (%s:%d)", fn, f.File, f.Line)
+	case f.Obj != "":
+		return fmt.Sprintf("%s (in %s)", fn, f.Obj)
+	}
+
+	return fn
+}
@@ -7,6 +7,7 @@ require (
 	github.com/creack/pty v1.1.24
 	github.com/essentialkaos/check v1.4.1
 	github.com/essentialkaos/ek/v13 v13.30.1
+	golang.org/x/sys v0.33.0
 )
 
 require (
@@ -14,5 +15,4 @@ require (
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
-	golang.org/x/sys v0.33.0 // indirect
 )
@@ -16,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/essentialkaos/ek/v13/fmtutil"
 	"github.com/essentialkaos/ek/v13/fsutil"
 	"github.com/essentialkaos/ek/v13/strutil"
 
@@ -50,6 +51,11 @@ func Parse(file string) (*recipe.Recipe, error) {
 	return parseRecipeFile(file)
 }
 
+// ParseWrapper parses memory checkers list (e.g. "asan,ubsan") as wrapper info
+func ParseWrapper(value string) (*recipe.Wrapper, error) {
+	return getOptionWrapperValue(recipe.OPTION_WRAPPER, strutil.Fields(value))
+}
+
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 // parseRecipeFile parce recipe file
@@ -89,7 +95,7 @@ func parseRecipeData(file string, reader io.Reader) (*recipe.Recipe, error) {
 			return nil, fmt.Errorf("Parsing error in line %d: %v", lineNum, err)
 		}
 
-		if !e.info.Global && len(result.Commands) == 0 {
+		if !e.info.Global && result.LastCommand() == nil {
 			return nil, fmt.Errorf("Parsing error in line %d: keyword %q is not allowed there", lineNum, e.info.Keyword)
 		}
 
@@ -123,7 +129,7 @@ func parseLine(line string) (*entity, error) {
 
 	keyword := fields[0]
 
-	info := getTokenInfo(keyword)
+	info := getTokenInfo(keyword, isGlobal)
 	tag := extractTag(keyword)
 
 	if info.Keyword == "" || info.Global != isGlobal {
@@ -161,7 +167,14 @@ func appendData(r *recipe.Recipe, e *entity, line uint16) error {
 		return processGlobalEntity(r, e, line)
 	}
 
-	return r.Commands.Last().AddAction(
+	switch e.info.Keyword {
+	case recipe.OPTION_ISOLATION, recipe.OPTION_LIMIT,
+		recipe.OPTION_DEADLINE, recipe.OPTION_BENCHMARK,
+		recipe.OPTION_WRAPPER:
+		return applyCommandOption(r, r.LastCommand(), e)
+	}
+
+	return r.LastCommand().AddAction(
 		&recipe.Action{
 			Name:      e.info.Keyword,
 			Arguments: e.args,
@@ -181,8 +194,11 @@ func processGlobalEntity(r *recipe.Recipe, e *entity, line uint16) error {
 		err = r.AddVariable(e.args[0], e.args[1])
 
 	case recipe.KEYWORD_COMMAND:
-		if e.isGroup && len(r.Commands) == 0 {
+		switch {
+		case e.isGroup && len(r.Commands) == 0:
 			return fmt.Errorf("Group command (with prefix +) cannot be defined as first in a recipe")
+		case e.isGroup && r.LastCommand().IsHook():
+			return fmt.Errorf("Group command (with prefix +) cannot be defined after hook command")
 		}
 
 		err = r.AddCommand(recipe.NewCommand(e.args, line), e.tag, e.isGroup)
@@ -222,6 +238,68 @@ func applyGlobalOption(r *recipe.Recipe, e *entity, line uint16) error {
 
 	case recipe.OPTION_DELAY:
 		r.Delay, err = getOptionFloatValue(e.info.Keyword, e.args[0])
+
+	case recipe.OPTION_AUTO_RESTORE:
+		r.AutoRestore, err = getOptionBoolValue(e.info.Keyword, e.args[0])
+
+	case recipe.OPTION_ISOLATION:
+		r.Isolation, err = getOptionIsolationValue(e.info.Keyword, e.args)
+		r.RequireRoot = r.RequireRoot || r.Isolation.IsEnabled()
+
+	case recipe.OPTION_LIMIT:
+		if r.Limits == nil {
+			r.Limits = &recipe.Limits{}
+		}
+
+		err = applyLimitValue(r.Limits, e.info.Keyword, e.args)
+		r.RequireRoot = r.RequireRoot || r.Limits.HasCgroupLimits()
+
+	case recipe.OPTION_DEADLINE:
+		r.Deadline, err = getOptionDurationValue(e.info.Keyword, e.args[0])
+
+	case recipe.OPTION_WRAPPER:
+		r.Wrapper, err = getOptionWrapperValue(e.info.Keyword, e.args)
+	}
+
+	return err
+}
+
+// applyCommandOption applies options to the command
+func applyCommandOption(r *recipe.Recipe, c *recipe.Command, e *entity) error {
+	var err error
+
+	switch e.info.Keyword {
+	case recipe.OPTION_ISOLATION:
+		c.Isolation, err = getOptionIsolationValue(e.info.Keyword, e.args)
+		r.RequireRoot = r.RequireRoot || c.Isolation.IsEnabled()
+
+	case recipe.OPTION_LIMIT:
+		if c.Limits == nil {
+			c.Limits = &recipe.Limits{}
+		}
+
+		err = applyLimitValue(c.Limits, e.info.Keyword, e.args)
+		r.RequireRoot = r.RequireRoot || c.Limits.HasCgroupLimits()
+
+	case recipe.OPTION_DEADLINE:
+		c.Deadline, err = getOptionDurationValue(e.info.Keyword, e.args[0])
+
+	case recipe.OPTION_BENCHMARK:
+		var iterations uint64
+
+		iterations, err = getOptionUintValue(e.info.Keyword, e.args[0])
+
+		if err == nil && iterations > recipe.MAX_BENCHMARK_ITERATIONS {
+			err = fmt.Errorf(
+				"%q is not allowed as value for %s: value must be less than or equal to %d",
+				e.args[0], e.info.Keyword, recipe.MAX_BENCHMARK_ITERATIONS,
+			)
+		}
+
+		c.Iterations = int(iterations)
+
+	case recipe.OPTION_WRAPPER:
+		c.Wrapper, err = getOptionWrapperValue(e.info.Keyword, e.args)
 	}
 
 	return err
@@ -250,8 +328,132 @@ func getOptionFloatValue(keyword, value string) (float64, error) {
 	return v, nil
 }
 
-// getTokenInfo return token info by keyword
-func getTokenInfo(keyword string) recipe.TokenInfo {
+// getOptionIsolationValue parses option value as isolation info
+func getOptionIsolationValue(keyword string, values []string) (*recipe.Isolation, error) {
+	result := &recipe.Isolation{}
+
+	for _, value := range values {
+		switch strings.ToLower(value) {
+		case recipe.ISOLATION_MOUNT:
+			result.Mount = true
+		case recipe.ISOLATION_NETWORK:
+			result.Network = true
+		case recipe.ISOLATION_PID:
+			result.PID = true
+		case recipe.ISOLATION_ALL:
+			result.Mount, result.Network, result.PID = true, true, true
+		case recipe.ISOLATION_NONE:
+			if len(values) != 1 {
+				return nil, fmt.Errorf("%q can't be used with other values of %s", value, keyword)
+			}
+		default:
+			return nil, fmt.Errorf("%q is not allowed as value for %s", value, keyword)
+		}
+	}
+
+	return result, nil
+}
+
+// getOptionWrapperValue parses option value as memory checker wrapper info
+func getOptionWrapperValue(keyword string, values []string) (*recipe.Wrapper, error) {
+	result := &recipe.Wrapper{}
+
+	for _, value := range values {
+		switch strings.ToLower(value) {
+		case recipe.WRAPPER_VALGRIND:
+			result.Valgrind = true
+		case recipe.WRAPPER_ASAN:
+			result.ASan = true
+		case recipe.WRAPPER_UBSAN:
+			result.UBSan = true
+		case recipe.WRAPPER_NONE:
+			if len(values) != 1 {
+				return nil, fmt.Errorf("%q can't be used with other values of %s", value, keyword)
+			}
+		default:
+			return nil, fmt.Errorf("%q is not allowed as value for %s", value, keyword)
+		}
+	}
+
+	// Valgrind can't run binaries built with sanitizers
+	if result.Valgrind && (result.ASan || result.UBSan) {
+		return nil, fmt.Errorf("%q can't be used with sanitizers in %s", recipe.WRAPPER_VALGRIND, keyword)
+	}
+
+	return result, nil
+}
+
+// applyLimitValue parses resource limit and adds it to limits
+func applyLimitValue(limits *recipe.Limits, keyword string, values []string) error {
+	var err error
+
+	limitType, value := strings.ToLower(values[0]), values[1]
+
+	switch limitType {
+	case recipe.LIMIT_MEMORY:
+		limits.Memory, err = getOptionSizeValue(keyword, value)
+	case recipe.LIMIT_ADDRESS_SPACE:
+		limits.AddressSpace, err = getOptionSizeValue(keyword, value)
+	case recipe.LIMIT_CPU:
+		limits.CPU, err = getOptionFloatValue(keyword, strings.TrimSuffix(value, "%"))
+
+		if err == nil && limits.CPU <= 0 {
+			err = fmt.Errorf("%q is not allowed as value for %s: value must be greater than 0", value, keyword)
+		}
+	case recipe.LIMIT_PIDS:
+		limits.PIDs, err = getOptionUintValue(keyword, value)
+	case recipe.LIMIT_NOFILE:
+		limits.NoFile, err = getOptionUintValue(keyword, value)
+	case recipe.LIMIT_CPU_TIME:
+		limits.CPUTime, err = getOptionUintValue(keyword, value)
+	default:
+		return fmt.Errorf("Unknown type of %s: %q", keyword, values[0])
+	}
+
+	return err
+}
+
+// getOptionUintValue parses option value as positive integer number
+func getOptionUintValue(keyword, value string) (uint64, error) {
+	v, err := strconv.ParseUint(value, 10, 64)
+
+	switch {
+	case err != nil:
+		return 0, fmt.Errorf("%q is not allowed as value for %s: %v", value, keyword, err)
+	case v == 0:
+		return 0, fmt.Errorf("%q is not allowed as value for %s: value must be greater than 0", value, keyword)
+	}
+
+	return v, nil
+}
+
+// getOptionDurationValue parses option value as duration in seconds
+func getOptionDurationValue(keyword, value string) (float64, error) {
+	v, err := getOptionFloatValue(keyword, value)
+
+	if err == nil && v <= 0 {
+		return 0, fmt.Errorf("%q is not allowed as value for %s: value must be greater than 0", value, keyword)
+	}
+
+	return v, err
+}
+
+// getOptionSizeValue parses option value as size in bytes
+func getOptionSizeValue(keyword, value string) (uint64, error) {
+	v := fmtutil.ParseSize(value)
+
+	if v == 0 {
+		return 0, fmt.Errorf("%q is not allowed as value for %s: value must be a non-zero size", value, keyword)
+	}
+
+	return v, nil
+}
+
+// getTokenInfo return token info by keyword. If there are global and non-global
+// tokens with the same keyword, token with the given scope will be returned.
+func getTokenInfo(keyword string, isGlobal bool) recipe.TokenInfo {
+	var result recipe.TokenInfo
+
 	switch {
 	case strings.HasPrefix(keyword, recipe.KEYWORD_COMMAND+recipe.SYMBOL_SEPARATOR),
 		strings.HasPrefix(keyword, recipe.SYMBOL_COMMAND_GROUP+recipe.KEYWORD_COMMAND),
@@ -260,14 +462,20 @@ func getTokenInfo(keyword string) recipe.TokenInfo {
 	}
 
 	for _, token := range recipe.Tokens {
-		switch {
-		case token.Keyword == keyword,
-			recipe.SYMBOL_NEGATIVE_ACTION+token.Keyword == keyword:
+		if token.Keyword != keyword && recipe.SYMBOL_NEGATIVE_ACTION+token.Keyword != keyword {
+			continue
+		}
+
+		if token.Global == isGlobal {
 			return token
 		}
+
+		if result.Keyword == "" {
+			result = token
+		}
 	}
 
-	return recipe.TokenInfo{}
+	return result
 }
 
 // isUselessRecipeLine return if line doesn't contains recipe data
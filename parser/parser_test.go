@@ -12,6 +12,8 @@ import (
 	"testing"
 
 	. "github.com/essentialkaos/check"
+
+	R "github.com/essentialkaos/bibop/recipe"
 )
 
 // ////////////////////////////////////////////////////////////////////////////////// //
@@ -73,6 +75,11 @@ func (s *ParseSuite) TestGlobalErrors(c *C) {
 
 	c.Assert(err, DeepEquals, errors.New("Parsing error in line 3: Group command (with prefix +) cannot be defined as first in a recipe"))
 	c.Assert(recipe, IsNil)
+
+	recipe, err = Parse("../testdata/test10.recipe")
+
+	c.Assert(err, DeepEquals, errors.New("Parsing error in line 9: Group command (with prefix +) cannot be defined after hook command"))
+	c.Assert(recipe, IsNil)
 }
 
 func (s *ParseSuite) TestBasicParsing(c *C) {
@@ -90,7 +97,14 @@ func (s *ParseSuite) TestBasicParsing(c *C) {
 	c.Assert(recipe.Unbuffer, Equals, true)
 	c.Assert(recipe.HTTPSSkipVerify, Equals, true)
 	c.Assert(recipe.Delay, Equals, 1.23)
-	c.Assert(recipe.Commands, HasLen, 5)
+	c.Assert(recipe.AutoRestore, Equals, true)
+	c.Assert(recipe.Isolation.Network, Equals, true)
+	c.Assert(recipe.Isolation.Mount, Equals, false)
+	c.Assert(recipe.Limits.NoFile, Equals, uint64(1024))
+	c.Assert(recipe.Deadline, Equals, 30.0)
+	c.Assert(recipe.Wrapper.ASan, Equals, true)
+	c.Assert(recipe.Wrapper.UBSan, Equals, true)
+	c.Assert(recipe.Commands, HasLen, 6)
 	c.Assert(recipe.Packages, DeepEquals, []string{"package1", "package2"})
 
 	c.Assert(recipe.Commands[0].User, Equals, "nobody")
@@ -107,10 +121,36 @@ func (s *ParseSuite) TestBasicParsing(c *C) {
 	c.Assert(recipe.Commands[1].Cmdline, Equals, "echo test")
 	c.Assert(recipe.Commands[1].Description, Equals, "Simple echo command")
 	c.Assert(recipe.Commands[1].Actions, HasLen, 1)
+	c.Assert(recipe.Commands[1].GetIsolation().Mount, Equals, true)
+	c.Assert(recipe.Commands[1].GetIsolation().PID, Equals, true)
+	c.Assert(recipe.Commands[1].GetIsolation().Network, Equals, false)
+	c.Assert(recipe.Commands[2].GetIsolation(), Equals, recipe.Isolation)
+	c.Assert(recipe.Commands[1].GetLimits().Memory, Equals, uint64(64*1024*1024))
+	c.Assert(recipe.Commands[1].GetLimits().CPU, Equals, 50.0)
+	c.Assert(recipe.Commands[1].GetLimits().NoFile, Equals, uint64(1024))
+	c.Assert(recipe.Commands[2].GetLimits().Memory, Equals, uint64(0))
+	c.Assert(recipe.Commands[1].GetDeadline(), Equals, 5.0)
+	c.Assert(recipe.Commands[1].Iterations, Equals, 10)
+	c.Assert(recipe.Commands[2].GetDeadline(), Equals, 30.0)
+	c.Assert(recipe.Commands[2].Iterations, Equals, 0)
+	c.Assert(recipe.Commands[1].GetWrapper().IsEnabled(), Equals, false)
+	c.Assert(recipe.Commands[2].GetWrapper(), Equals, recipe.Wrapper)
 
 	c.Assert(recipe.Commands[2].GroupID, Equals, recipe.Commands[3].GroupID)
 
 	c.Assert(recipe.Commands[4].Tag, Equals, "special")
+
+	c.Assert(recipe.Commands[5].Actions[0].Arguments, DeepEquals, []string{"0,64-78", "5"})
+
+	c.Assert(recipe.Setup, HasLen, 1)
+	c.Assert(recipe.BeforeEach, HasLen, 1)
+	c.Assert(recipe.AfterEach, HasLen, 1)
+	c.Assert(recipe.Hooks(), HasLen, 3)
+	c.Assert(recipe.Setup[0].Actions, HasLen, 1)
+	c.Assert(recipe.BeforeEach[0].Actions, HasLen, 1)
+	c.Assert(recipe.AfterEach[0].Actions, HasLen, 1)
+	c.Assert(recipe.AfterEach[0].IsHook(), Equals, true)
+	c.Assert(recipe.LastCommand(), Equals, recipe.AfterEach[0])
 }
 
 func (s *ParseSuite) TestOptionsParsing(c *C) {
@@ -142,6 +182,95 @@ func (s *ParseSuite) TestOptionsParsing(c *C) {
 	_, err = getOptionFloatValue("test", "abcd")
 
 	c.Assert(err, NotNil)
+
+	i, err := getOptionIsolationValue("test", []string{"mount", "NETWORK"})
+
+	c.Assert(i.Mount, Equals, true)
+	c.Assert(i.Network, Equals, true)
+	c.Assert(i.PID, Equals, false)
+	c.Assert(err, IsNil)
+
+	i, err = getOptionIsolationValue("test", []string{"all"})
+
+	c.Assert(i.Mount, Equals, true)
+	c.Assert(i.Network, Equals, true)
+	c.Assert(i.PID, Equals, true)
+	c.Assert(err, IsNil)
+
+	i, err = getOptionIsolationValue("test", []string{"none"})
+
+	c.Assert(i.IsEnabled(), Equals, false)
+	c.Assert(err, IsNil)
+
+	_, err = getOptionIsolationValue("test", []string{"none", "pid"})
+
+	c.Assert(err, NotNil)
+
+	_, err = getOptionIsolationValue("test", []string{"abcd"})
+
+	c.Assert(err, NotNil)
+
+	w, err := getOptionWrapperValue("test", []string{"ASAN", "ubsan"})
+
+	c.Assert(w.ASan, Equals, true)
+	c.Assert(w.UBSan, Equals, true)
+	c.Assert(w.Valgrind, Equals, false)
+	c.Assert(err, IsNil)
+
+	w, err = getOptionWrapperValue("test", []string{"none"})
+
+	c.Assert(w.IsEnabled(), Equals, false)
+	c.Assert(err, IsNil)
+
+	_, err = getOptionWrapperValue("test", []string{"none", "asan"})
+
+	c.Assert(err, NotNil)
+
+	_, err = getOptionWrapperValue("test", []string{"valgrind", "asan"})
+
+	c.Assert(err, NotNil)
+
+	_, err = getOptionWrapperValue("test", []string{"abcd"})
+
+	c.Assert(err, NotNil)
+
+	w, err = ParseWrapper("asan,ubsan")
+
+	c.Assert(w.ASan, Equals, true)
+	c.Assert(w.UBSan, Equals, true)
+	c.Assert(err, IsNil)
+
+	l := &R.Limits{}
+
+	c.Assert(applyLimitValue(l, "test", []string{"memory", "1GB"}), IsNil)
+	c.Assert(applyLimitValue(l, "test", []string{"address-space", "2GB"}), IsNil)
+	c.Assert(applyLimitValue(l, "test", []string{"cpu", "150"}), IsNil)
+	c.Assert(applyLimitValue(l, "test", []string{"pids", "10"}), IsNil)
+	c.Assert(applyLimitValue(l, "test", []string{"nofile", "64"}), IsNil)
+	c.Assert(applyLimitValue(l, "test", []string{"cpu-time", "5"}), IsNil)
+	c.Assert(l, DeepEquals, &R.Limits{
+		Memory: 1024 * 1024 * 1024, CPU: 150, PIDs: 10,
+		NoFile: 64, CPUTime: 5, AddressSpace: 2 * 1024 * 1024 * 1024,
+	})
+
+	c.Assert(applyLimitValue(l, "test", []string{"abcd", "1"}), NotNil)
+	c.Assert(applyLimitValue(l, "test", []string{"memory", "abcd"}), NotNil)
+	c.Assert(applyLimitValue(l, "test", []string{"cpu", "0"}), NotNil)
+	c.Assert(applyLimitValue(l, "test", []string{"pids", "0"}), NotNil)
+	c.Assert(applyLimitValue(l, "test", []string{"nofile", "-1"}), NotNil)
+
+	d, err := getOptionDurationValue("test", "2.5")
+
+	c.Assert(d, Equals, 2.5)
+	c.Assert(err, IsNil)
+
+	_, err = getOptionDurationValue("test", "0")
+
+	c.Assert(err, NotNil)
+
+	_, err = getOptionDurationValue("test", "abcd")
+
+	c.Assert(err, NotNil)
 }
 
 func (s *ParseSuite) TestTokenParsingErrors(c *C) {
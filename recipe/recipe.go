@@ -28,9 +28,49 @@ const MAX_VAR_NESTING int = 32
 // MAX_VARIABLE_SIZE is maximum length of variable value
 const MAX_VARIABLE_SIZE int = 512
 
+// MAX_BENCHMARK_ITERATIONS is maximum number of command iterations in
+// benchmark mode
+const MAX_BENCHMARK_ITERATIONS = 10000
+
 // TEARDOWN_TAG is teardown tag
 const TEARDOWN_TAG = "teardown"
 
+// SETUP_TAG is tag of commands executed before all other commands
+const SETUP_TAG = "setup"
+
+// BEFORE_EACH_TAG is tag of commands executed before every command group
+const BEFORE_EACH_TAG = "before-each"
+
+// AFTER_EACH_TAG is tag of commands executed after every command group
+const AFTER_EACH_TAG = "after-each"
+
+// Isolation types
+const (
+	ISOLATION_MOUNT   = "mount"
+	ISOLATION_NETWORK = "network"
+	ISOLATION_PID     = "pid"
+	ISOLATION_ALL     = "all"
+	ISOLATION_NONE    = "none"
+)
+
+// Wrapper types
+const (
+	WRAPPER_VALGRIND = "valgrind"
+	WRAPPER_ASAN     = "asan"
+	WRAPPER_UBSAN    = "ubsan"
+	WRAPPER_NONE     = "none"
+)
+
+// Resource limits types
+const (
+	LIMIT_MEMORY        = "memory"
+	LIMIT_CPU           = "cpu"
+	LIMIT_PIDS          = "pids"
+	LIMIT_NOFILE        = "nofile"
+	LIMIT_CPU_TIME      = "cpu-time"
+	LIMIT_ADDRESS_SPACE = "address-space"
+)
+
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 // Recipe contains recipe data
@@ -41,14 +81,27 @@ type Recipe struct {
 	File            string   // Path to recipe
 	Dir             string   // Working dir
 	Delay           float64  // Delay between commands
+	Deadline        float64  // Max duration of every command
 	UnsafeActions   bool     // Allow unsafe actions
 	RequireRoot     bool     // Require root privileges
 	FastFinish      bool     // Fast finish flag
 	LockWorkdir     bool     // Locking workdir flag
 	Unbuffer        bool     // Disabled IO buffering
 	HTTPSSkipVerify bool     // Disable certificate verification
+	AutoRestore     bool     // Restore all backups after recipe processing
+
+	Isolation *Isolation // Namespace isolation for all commands
+	Limits    *Limits    // Resource limits for all commands
+	Wrapper   *Wrapper   // Memory checker wrapper for all commands
+
+	Setup      Commands // Commands executed before all other commands
+	BeforeEach Commands // Commands executed before every command group
+	AfterEach  Commands // Commands executed after every command group
+
+	Data *Storage // Data storage
 
-	variables *Variables // Variables
+	variables   *Variables // Variables
+	lastCommand *Command   // The last added command or hook
 }
 
 // Commands is a slice with commands
@@ -66,12 +119,82 @@ type Command struct {
 	Recipe      *Recipe   // Link to recipe
 	Line        uint16    // Line in recipe file
 	Started     time.Time // Command execution start time
+	Aborted     chan bool // Channel closed on command execution abort
+
+	Isolation *Isolation     // Namespace isolation (overrides recipe isolation)
+	Limits    *Limits        // Resource limits (overrides recipe limits)
+	Usage     *ResourceUsage // Resources used by command process
+	Crash     *CrashInfo     // Info about command process crash
+	Wrapper   *Wrapper       // Memory checker wrapper (overrides recipe wrapper)
+	Findings  []*Finding     // Errors found by memory checker
+	Output    string         // The last lines of output of failed command
+
+	Deadline   float64          // Max duration of command (overrides recipe deadline)
+	Iterations int              // Number of iterations in benchmark mode
+	Benchmark  *BenchmarkResult // Benchmark results
 
 	GroupID uint8 // Unique command group ID
 
 	Data *Storage // Data storage
 }
 
+// Isolation contains info about namespaces used for command isolation
+type Isolation struct {
+	Mount   bool // Private mount namespace with tmpfs overlays
+	Network bool // Private network namespace with loopback only
+	PID     bool // Private PID namespace
+}
+
+// Wrapper contains info about memory checkers used for running command
+type Wrapper struct {
+	Valgrind bool // Run command under valgrind memcheck
+	ASan     bool // Collect AddressSanitizer reports
+	UBSan    bool // Collect UndefinedBehaviorSanitizer reports
+}
+
+// Finding contains info about error found by memory checker
+type Finding struct {
+	Tool     string // Tool name (valgrind, asan or ubsan)
+	Summary  string // Short description of error
+	Location string // Location of error (the first stack frame)
+	Report   string // Full error report
+}
+
+// Limits contains resource limits for command process
+type Limits struct {
+	Memory       uint64  // Memory limit in bytes (cgroup)
+	CPU          float64 // CPU limit in percents of one CPU core (cgroup)
+	PIDs         uint64  // Maximum number of processes (cgroup)
+	NoFile       uint64  // Maximum number of open files (rlimit)
+	CPUTime      uint64  // CPU time limit in seconds (rlimit)
+	AddressSpace uint64  // Virtual memory limit in bytes (rlimit)
+}
+
+// ResourceUsage contains info about resources used by command process
+type ResourceUsage struct {
+	MaxRSS     uint64        // Peak resident set size in bytes
+	UserTime   time.Duration // CPU time spent in user mode
+	SystemTime time.Duration // CPU time spent in kernel mode
+	IORead     uint64        // Number of bytes read from block devices
+	IOWrite    uint64        // Number of bytes written to block devices
+}
+
+// CrashInfo contains info about crash of command process
+type CrashInfo struct {
+	Signal     string // Signal name (e.g. SIGSEGV)
+	CoreDumped bool   // True if process dumped core
+	CoreFile   string // Path to saved core dump
+}
+
+// BenchmarkResult contains command benchmark results
+type BenchmarkResult struct {
+	Iterations   int           // Number of iterations
+	Min          time.Duration // Minimal duration
+	Mean         time.Duration // Mean duration
+	P95          time.Duration // 95th percentile of duration
+	BaselineMean time.Duration // Mean duration from baseline
+}
+
 // Actions is a slice with actions
 type Actions []*Action
 
@@ -124,6 +247,7 @@ func NewRecipe(file string) *Recipe {
 		File:        file,
 		LockWorkdir: true,
 
+		Data:      &Storage{},
 		variables: &Variables{index: map[string]*Variable{}},
 	}
 }
@@ -137,6 +261,10 @@ func NewCommand(args []string, line uint16) *Command {
 
 // AddCommand appends command to command slice
 func (r *Recipe) AddCommand(cmd *Command, tag string, isNested bool) error {
+	if isNested && isHookTag(tag) {
+		return fmt.Errorf("Command with tag %q can't be a part of command group", tag)
+	}
+
 	cmd.Recipe = r
 	cmd.Tag = tag
 
@@ -152,6 +280,20 @@ func (r *Recipe) AddCommand(cmd *Command, tag string, isNested bool) error {
 		cmd.Description = renderVars(r, cmd.Description)
 	}
 
+	r.lastCommand = cmd
+
+	switch tag {
+	case SETUP_TAG:
+		r.Setup = append(r.Setup, cmd)
+		return nil
+	case BEFORE_EACH_TAG:
+		r.BeforeEach = append(r.BeforeEach, cmd)
+		return nil
+	case AFTER_EACH_TAG:
+		r.AfterEach = append(r.AfterEach, cmd)
+		return nil
+	}
+
 	if len(r.Commands) != 0 {
 		if isNested {
 			cmd.GroupID = r.Commands.Last().GroupID
@@ -165,6 +307,22 @@ func (r *Recipe) AddCommand(cmd *Command, tag string, isNested bool) error {
 	return nil
 }
 
+// LastCommand returns the last added command or hook
+func (r *Recipe) LastCommand() *Command {
+	return r.lastCommand
+}
+
+// Hooks returns all setup, before-each and after-each commands
+func (r *Recipe) Hooks() Commands {
+	var result Commands
+
+	result = append(result, r.Setup...)
+	result = append(result, r.BeforeEach...)
+	result = append(result, r.AfterEach...)
+
+	return result
+}
+
 // AddVariable adds new RO variable
 func (r *Recipe) AddVariable(name, value string) error {
 	if strings.Contains(value, "{"+name+"}") {
@@ -322,6 +480,65 @@ func (c *Command) String() string {
 	return fmt.Sprintf("Command{%s}", info)
 }
 
+// GetIsolation returns namespace isolation info for command
+func (c *Command) GetIsolation() *Isolation {
+	switch {
+	case c.Isolation != nil:
+		return c.Isolation
+	case c.Recipe != nil:
+		return c.Recipe.Isolation
+	}
+
+	return nil
+}
+
+// GetWrapper returns memory checker wrapper info for command
+func (c *Command) GetWrapper() *Wrapper {
+	switch {
+	case c.Wrapper != nil:
+		return c.Wrapper
+	case c.Recipe != nil:
+		return c.Recipe.Wrapper
+	}
+
+	return nil
+}
+
+// GetLimits returns resource limits for command. Limits defined for command
+// override limits defined for recipe.
+func (c *Command) GetLimits() *Limits {
+	var result Limits
+
+	if c.Recipe != nil && c.Recipe.Limits != nil {
+		result = *c.Recipe.Limits
+	}
+
+	if c.Limits != nil {
+		result.Merge(c.Limits)
+	}
+
+	if result.IsEmpty() {
+		return nil
+	}
+
+	return &result
+}
+
+// GetDeadline returns max duration of command in seconds
+func (c *Command) GetDeadline() float64 {
+	if c.Deadline > 0 || c.Recipe == nil {
+		return c.Deadline
+	}
+
+	return c.Recipe.Deadline
+}
+
+// IsHook returns true if the current command is setup, before-each or after-each
+// hook
+func (c *Command) IsHook() bool {
+	return isHookTag(c.Tag)
+}
+
 // IsHollow returns true if the current command is "hollow" i.e., this command
 // does not execute any of the binaries on the system
 func (c *Command) IsHollow() bool {
@@ -330,6 +547,77 @@ func (c *Command) IsHollow() bool {
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
+// IsEnabled returns true if at least one type of isolation is enabled
+func (i *Isolation) IsEnabled() bool {
+	return i != nil && (i.Mount || i.Network || i.PID)
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// IsEnabled returns true if at least one memory checker is enabled
+func (w *Wrapper) IsEnabled() bool {
+	return w != nil && (w.Valgrind || w.ASan || w.UBSan)
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Merge copies all defined limits from given limits
+func (l *Limits) Merge(limits *Limits) {
+	if limits == nil {
+		return
+	}
+
+	if limits.Memory != 0 {
+		l.Memory = limits.Memory
+	}
+
+	if limits.CPU != 0 {
+		l.CPU = limits.CPU
+	}
+
+	if limits.PIDs != 0 {
+		l.PIDs = limits.PIDs
+	}
+
+	if limits.NoFile != 0 {
+		l.NoFile = limits.NoFile
+	}
+
+	if limits.CPUTime != 0 {
+		l.CPUTime = limits.CPUTime
+	}
+
+	if limits.AddressSpace != 0 {
+		l.AddressSpace = limits.AddressSpace
+	}
+}
+
+// IsEmpty returns true if no limits are defined
+func (l *Limits) IsEmpty() bool {
+	return l == nil || (!l.HasCgroupLimits() && !l.HasRLimits())
+}
+
+// HasCgroupLimits returns true if limits require cgroup
+func (l *Limits) HasCgroupLimits() bool {
+	return l != nil && (l.Memory != 0 || l.CPU != 0 || l.PIDs != 0)
+}
+
+// HasRLimits returns true if limits contain process rlimits
+func (l *Limits) HasRLimits() bool {
+	return l != nil && (l.NoFile != 0 || l.CPUTime != 0 || l.AddressSpace != 0)
+}
+
+// CPUTime returns total CPU time used by process
+func (u *ResourceUsage) CPUTime() time.Duration {
+	if u == nil {
+		return 0
+	}
+
+	return u.UserTime + u.SystemTime
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
 // Set adds new object into storage with given key
 func (s *Storage) Set(key string, value interface{}) {
 	if s.data == nil {
@@ -561,3 +849,13 @@ func renderVars(r *Recipe, data string) string {
 
 	return data
 }
+
+// isHookTag returns true if given tag is setup, before-each or after-each tag
+func isHookTag(tag string) bool {
+	switch tag {
+	case SETUP_TAG, BEFORE_EACH_TAG, AFTER_EACH_TAG:
+		return true
+	}
+
+	return false
+}
@@ -41,6 +41,7 @@ func (s *RecipeSuite) TestRecipeConstructor(c *C) {
 	c.Assert(r.UnsafeActions, Equals, false)
 	c.Assert(r.RequireRoot, Equals, false)
 	c.Assert(r.Commands, HasLen, 0)
+	c.Assert(r.Data, NotNil)
 	c.Assert(r.variables.index, HasLen, 0)
 }
 
@@ -429,6 +430,125 @@ func (s *RecipeSuite) TestTags(c *C) {
 	c.Assert(r.HasTeardown(), Equals, false)
 }
 
+func (s *RecipeSuite) TestHooks(c *C) {
+	r := NewRecipe("/home/user/test.recipe")
+	c1, c2, c3, c4 := &Command{}, &Command{}, &Command{}, &Command{}
+
+	c.Assert(r.LastCommand(), IsNil)
+
+	c.Assert(r.AddCommand(c1, "", false), IsNil)
+	c.Assert(r.AddCommand(c2, SETUP_TAG, false), IsNil)
+	c.Assert(r.AddCommand(c3, BEFORE_EACH_TAG, false), IsNil)
+	c.Assert(r.AddCommand(c4, AFTER_EACH_TAG, false), IsNil)
+
+	c.Assert(r.Commands, HasLen, 1)
+	c.Assert(r.Setup, DeepEquals, Commands{c2})
+	c.Assert(r.BeforeEach, DeepEquals, Commands{c3})
+	c.Assert(r.AfterEach, DeepEquals, Commands{c4})
+	c.Assert(r.Hooks(), DeepEquals, Commands{c2, c3, c4})
+	c.Assert(r.LastCommand(), Equals, c4)
+	c.Assert(c1.IsHook(), Equals, false)
+	c.Assert(c3.IsHook(), Equals, true)
+	c.Assert(c3.Index(), Equals, -1)
+
+	err := r.AddCommand(&Command{}, AFTER_EACH_TAG, true)
+	c.Assert(err, ErrorMatches, `Command with tag "after-each" can't be a part of command group`)
+}
+
+func (s *RecipeSuite) TestIsolation(c *C) {
+	r := NewRecipe("/home/user/test.recipe")
+	c1, c2 := &Command{}, &Command{}
+
+	c.Assert(c1.GetIsolation(), IsNil)
+	c.Assert(c1.GetIsolation().IsEnabled(), Equals, false)
+
+	r.AddCommand(c1, "", false)
+	r.AddCommand(c2, "", false)
+
+	r.Isolation = &Isolation{Network: true}
+	c2.Isolation = &Isolation{}
+
+	c.Assert(c1.GetIsolation(), Equals, r.Isolation)
+	c.Assert(c1.GetIsolation().IsEnabled(), Equals, true)
+	c.Assert(c2.GetIsolation(), Equals, c2.Isolation)
+	c.Assert(c2.GetIsolation().IsEnabled(), Equals, false)
+}
+
+func (s *RecipeSuite) TestWrapper(c *C) {
+	r := NewRecipe("/home/user/test.recipe")
+	c1, c2 := &Command{}, &Command{}
+
+	c.Assert(c1.GetWrapper(), IsNil)
+	c.Assert(c1.GetWrapper().IsEnabled(), Equals, false)
+
+	r.AddCommand(c1, "", false)
+	r.AddCommand(c2, "", false)
+
+	r.Wrapper = &Wrapper{ASan: true}
+	c2.Wrapper = &Wrapper{}
+
+	c.Assert(c1.GetWrapper(), Equals, r.Wrapper)
+	c.Assert(c1.GetWrapper().IsEnabled(), Equals, true)
+	c.Assert(c2.GetWrapper(), Equals, c2.Wrapper)
+	c.Assert(c2.GetWrapper().IsEnabled(), Equals, false)
+}
+
+func (s *RecipeSuite) TestLimits(c *C) {
+	r := NewRecipe("/home/user/test.recipe")
+	c1, c2 := &Command{}, &Command{}
+
+	c.Assert(c1.GetLimits(), IsNil)
+
+	r.AddCommand(c1, "", false)
+	r.AddCommand(c2, "", false)
+
+	c.Assert(c1.GetLimits(), IsNil)
+
+	r.Limits = &Limits{Memory: 1024, NoFile: 64}
+	c2.Limits = &Limits{Memory: 2048, CPU: 50}
+
+	c.Assert(c1.GetLimits(), DeepEquals, &Limits{Memory: 1024, NoFile: 64})
+	c.Assert(c2.GetLimits(), DeepEquals, &Limits{Memory: 2048, CPU: 50, NoFile: 64})
+	c.Assert(c2.GetLimits().HasCgroupLimits(), Equals, true)
+	c.Assert(c2.GetLimits().HasRLimits(), Equals, true)
+	c.Assert(r.Limits.HasCgroupLimits(), Equals, true)
+
+	var l *Limits
+
+	c.Assert(l.IsEmpty(), Equals, true)
+	c.Assert(l.HasCgroupLimits(), Equals, false)
+	c.Assert(l.HasRLimits(), Equals, false)
+
+	l = &Limits{}
+	l.Merge(nil)
+	l.Merge(&Limits{PIDs: 1, CPUTime: 2, AddressSpace: 3})
+
+	c.Assert(l, DeepEquals, &Limits{PIDs: 1, CPUTime: 2, AddressSpace: 3})
+
+	u := &ResourceUsage{UserTime: time.Second, SystemTime: time.Second}
+
+	c.Assert(u.CPUTime(), Equals, 2*time.Second)
+
+	u = nil
+
+	c.Assert(u.CPUTime(), Equals, time.Duration(0))
+}
+
+func (s *RecipeSuite) TestDeadline(c *C) {
+	r := NewRecipe("/home/user/test.recipe")
+	c1, c2 := &Command{}, &Command{Deadline: 5}
+
+	c.Assert(c1.GetDeadline(), Equals, 0.0)
+
+	r.AddCommand(c1, "", false)
+	r.AddCommand(c2, "", false)
+
+	r.Deadline = 30
+
+	c.Assert(c1.GetDeadline(), Equals, 30.0)
+	c.Assert(c2.GetDeadline(), Equals, 5.0)
+}
+
 func (s *RecipeSuite) TestNesting(c *C) {
 	r := NewRecipe("/home/user/test.recipe")
 
@@ -23,9 +23,16 @@ const (
 	OPTION_UNBUFFER          = "unbuffer"
 	OPTION_HTTPS_SKIP_VERIFY = "https-skip-verify"
 	OPTION_DELAY             = "delay"
+	OPTION_AUTO_RESTORE      = "auto-restore"
+	OPTION_ISOLATION         = "isolation"
+	OPTION_LIMIT             = "limit"
+	OPTION_DEADLINE          = "deadline"
+	OPTION_BENCHMARK         = "benchmark"
+	OPTION_WRAPPER           = "wrapper"
 
-	ACTION_EXIT = "exit"
-	ACTION_WAIT = "wait"
+	ACTION_EXIT    = "exit"
+	ACTION_RUNNING = "running"
+	ACTION_WAIT    = "wait"
 
 	ACTION_EXPECT          = "expect"
 	ACTION_WAIT_OUTPUT     = "wait-output"
@@ -61,18 +68,51 @@ const (
 	ACTION_TRUNCATE = "truncate"
 	ACTION_CLEANUP  = "cleanup"
 
+	ACTION_FILE_CAP            = "file-cap"
+	ACTION_FILE_CAP_SET        = "file-cap-set"
+	ACTION_XATTR               = "xattr"
+	ACTION_XATTR_SET           = "xattr-set"
+	ACTION_SELINUX_CONTEXT     = "selinux-context"
+	ACTION_SELINUX_CONTEXT_SET = "selinux-context-set"
+	ACTION_FILE_ATTR           = "file-attr"
+	ACTION_FILE_ATTR_SET       = "file-attr-set"
+	ACTION_ACL                 = "acl"
+	ACTION_ACL_DEFAULT         = "acl-default"
+
 	ACTION_BACKUP         = "backup"
 	ACTION_BACKUP_RESTORE = "backup-restore"
 
-	ACTION_PROCESS_WORKS = "process-works"
-	ACTION_WAIT_PID      = "wait-pid"
-	ACTION_WAIT_FS       = "wait-fs"
-	ACTION_WAIT_CONNECT  = "wait-connect"
-	ACTION_CONNECT       = "connect"
-	ACTION_APP           = "app"
-	ACTION_SIGNAL        = "signal"
-	ACTION_ENV           = "env"
-	ACTION_ENV_SET       = "env-set"
+	ACTION_SNAPSHOT         = "snapshot"
+	ACTION_SNAPSHOT_CREATED = "snapshot-created"
+	ACTION_SNAPSHOT_DELETED = "snapshot-deleted"
+	ACTION_SNAPSHOT_CHANGED = "snapshot-changed"
+
+	ACTION_PROCESS_WORKS    = "process-works"
+	ACTION_WAIT_PID         = "wait-pid"
+	ACTION_PROCESS_EXIST    = "process-exist"
+	ACTION_PROCESS_COUNT    = "process-count"
+	ACTION_PROCESS_USER     = "process-user"
+	ACTION_PROCESS_PARENT   = "process-parent"
+	ACTION_PROCESS_STATE    = "process-state"
+	ACTION_WAIT_PROCESS     = "wait-process"
+	ACTION_WAIT_FS          = "wait-fs"
+	ACTION_WAIT_FS_MODIFY   = "wait-fs-modify"
+	ACTION_WAIT_FS_SIZE     = "wait-fs-size"
+	ACTION_WAIT_FS_SETTLE   = "wait-fs-settle"
+	ACTION_WAIT_FS_CONTAINS = "wait-fs-contains"
+	ACTION_LOG_CONTAINS     = "log-contains"
+	ACTION_LOG_MATCH        = "log-match"
+	ACTION_WAIT_LOG         = "wait-log"
+	ACTION_WAIT_LOG_MATCH   = "wait-log-match"
+	ACTION_WAIT_CONNECT     = "wait-connect"
+	ACTION_CONNECT          = "connect"
+	ACTION_LISTEN           = "listen"
+	ACTION_LISTEN_OWNER     = "listen-owner"
+	ACTION_LISTEN_LOCAL     = "listen-local"
+	ACTION_APP              = "app"
+	ACTION_SIGNAL           = "signal"
+	ACTION_ENV              = "env"
+	ACTION_ENV_SET          = "env-set"
 
 	ACTION_USER_EXIST  = "user-exist"
 	ACTION_USER_ID     = "user-id"
@@ -83,11 +123,27 @@ const (
 	ACTION_GROUP_EXIST = "group-exist"
 	ACTION_GROUP_ID    = "group-id"
 
+	ACTION_PROC_UID    = "proc-uid"
+	ACTION_PROC_GID    = "proc-gid"
+	ACTION_PROC_GROUP  = "proc-group"
+	ACTION_PROC_CAP    = "proc-cap"
+	ACTION_PROC_ENV    = "proc-env"
+	ACTION_PROC_CWD    = "proc-cwd"
+	ACTION_PROC_UMASK  = "proc-umask"
+	ACTION_PROC_NICE   = "proc-nice"
+	ACTION_PROC_CGROUP = "proc-cgroup"
+	ACTION_PROC_EXE    = "proc-exe"
+
 	ACTION_SERVICE_PRESENT = "service-present"
 	ACTION_SERVICE_ENABLED = "service-enabled"
 	ACTION_SERVICE_WORKS   = "service-works"
 	ACTION_WAIT_SERVICE    = "wait-service"
 
+	ACTION_SERVICE_PROPERTY          = "service-property"
+	ACTION_SERVICE_PROPERTY_CONTAINS = "service-property-contains"
+	ACTION_JOURNAL_CONTAINS          = "journal-contains"
+	ACTION_JOURNAL_MATCH             = "journal-match"
+
 	ACTION_HTTP_STATUS     = "http-status"
 	ACTION_HTTP_HEADER     = "http-header"
 	ACTION_HTTP_CONTAINS   = "http-contains"
@@ -107,6 +163,15 @@ const (
 	ACTION_PYTHON2_PACKAGE = "python2-package"
 	ACTION_PYTHON3_PACKAGE = "python3-package"
 
+	ACTION_MAX_RSS      = "max-rss"
+	ACTION_MAX_CPU_TIME = "max-cpu-time"
+	ACTION_MAX_IO_READ  = "max-io-read"
+	ACTION_MAX_IO_WRITE = "max-io-write"
+	ACTION_MAX_DURATION = "max-duration"
+
+	ACTION_PROC_SNAPSHOT = "proc-snapshot"
+	ACTION_PROC_GROWTH   = "proc-growth"
+
 	ACTION_TEMPLATE = "template"
 )
 
@@ -136,8 +201,19 @@ var Tokens = []TokenInfo{
 	{OPTION_UNBUFFER, 1, 1, true, false},
 	{OPTION_HTTPS_SKIP_VERIFY, 1, 1, true, false},
 	{OPTION_DELAY, 1, 1, true, false},
+	{OPTION_AUTO_RESTORE, 1, 1, true, false},
+	{OPTION_ISOLATION, 1, 3, true, false},
+	{OPTION_ISOLATION, 1, 3, false, false},
+	{OPTION_LIMIT, 2, 2, true, false},
+	{OPTION_LIMIT, 2, 2, false, false},
+	{OPTION_DEADLINE, 1, 1, true, false},
+	{OPTION_DEADLINE, 1, 1, false, false},
+	{OPTION_BENCHMARK, 1, 1, false, false},
+	{OPTION_WRAPPER, 1, 2, true, false},
+	{OPTION_WRAPPER, 1, 2, false, false},
 
 	{ACTION_EXIT, 1, 2, false, true},
+	{ACTION_RUNNING, 1, 1, false, false},
 	{ACTION_WAIT, 1, 1, false, false},
 
 	{ACTION_EXPECT, 1, 2, false, false},
@@ -174,14 +250,47 @@ var Tokens = []TokenInfo{
 	{ACTION_TRUNCATE, 1, 1, false, false},
 	{ACTION_CLEANUP, 1, 1, false, false},
 
+	{ACTION_FILE_CAP, 2, 999, false, true},
+	{ACTION_FILE_CAP_SET, 2, 999, false, false},
+	{ACTION_XATTR, 2, 3, false, true},
+	{ACTION_XATTR_SET, 3, 3, false, false},
+	{ACTION_SELINUX_CONTEXT, 2, 2, false, true},
+	{ACTION_SELINUX_CONTEXT_SET, 2, 2, false, false},
+	{ACTION_FILE_ATTR, 2, 999, false, true},
+	{ACTION_FILE_ATTR_SET, 2, 999, false, false},
+	{ACTION_ACL, 2, 999, false, true},
+	{ACTION_ACL_DEFAULT, 2, 999, false, true},
+
 	{ACTION_BACKUP, 1, 1, false, false},
 	{ACTION_BACKUP_RESTORE, 1, 1, false, false},
 
+	{ACTION_SNAPSHOT, 2, 2, false, false},
+	{ACTION_SNAPSHOT_CREATED, 1, 999, false, true},
+	{ACTION_SNAPSHOT_DELETED, 1, 999, false, true},
+	{ACTION_SNAPSHOT_CHANGED, 1, 999, false, true},
+
 	{ACTION_PROCESS_WORKS, 1, 1, false, true},
 	{ACTION_WAIT_PID, 1, 2, false, true},
+	{ACTION_PROCESS_EXIST, 2, 2, false, true},
+	{ACTION_PROCESS_COUNT, 3, 3, false, true},
+	{ACTION_PROCESS_USER, 3, 3, false, true},
+	{ACTION_PROCESS_PARENT, 3, 3, false, true},
+	{ACTION_PROCESS_STATE, 3, 3, false, true},
+	{ACTION_WAIT_PROCESS, 2, 3, false, true},
 	{ACTION_WAIT_FS, 1, 2, false, true},
+	{ACTION_WAIT_FS_MODIFY, 1, 2, false, false},
+	{ACTION_WAIT_FS_SIZE, 2, 3, false, false},
+	{ACTION_WAIT_FS_SETTLE, 2, 3, false, false},
+	{ACTION_WAIT_FS_CONTAINS, 2, 3, false, false},
+	{ACTION_LOG_CONTAINS, 2, 2, false, true},
+	{ACTION_LOG_MATCH, 2, 2, false, true},
+	{ACTION_WAIT_LOG, 2, 3, false, false},
+	{ACTION_WAIT_LOG_MATCH, 2, 3, false, false},
 	{ACTION_WAIT_CONNECT, 2, 3, false, true},
 	{ACTION_CONNECT, 2, 3, false, true},
+	{ACTION_LISTEN, 2, 2, false, true},
+	{ACTION_LISTEN_OWNER, 4, 4, false, true},
+	{ACTION_LISTEN_LOCAL, 2, 2, false, false},
 	{ACTION_APP, 1, 1, false, true},
 	{ACTION_SIGNAL, 1, 2, false, false},
 	{ACTION_ENV, 2, 2, false, true},
@@ -196,11 +305,27 @@ var Tokens = []TokenInfo{
 	{ACTION_GROUP_EXIST, 1, 1, false, true},
 	{ACTION_GROUP_ID, 2, 2, false, true},
 
+	{ACTION_PROC_UID, 1, 2, false, true},
+	{ACTION_PROC_GID, 1, 2, false, true},
+	{ACTION_PROC_GROUP, 1, 2, false, true},
+	{ACTION_PROC_CAP, 2, 3, false, true},
+	{ACTION_PROC_ENV, 2, 3, false, true},
+	{ACTION_PROC_CWD, 1, 2, false, true},
+	{ACTION_PROC_UMASK, 1, 2, false, true},
+	{ACTION_PROC_NICE, 1, 2, false, true},
+	{ACTION_PROC_CGROUP, 1, 2, false, true},
+	{ACTION_PROC_EXE, 1, 2, false, true},
+
 	{ACTION_SERVICE_PRESENT, 1, 1, false, true},
 	{ACTION_SERVICE_ENABLED, 1, 1, false, true},
 	{ACTION_SERVICE_WORKS, 1, 1, false, true},
 	{ACTION_WAIT_SERVICE, 1, 2, false, true},
 
+	{ACTION_SERVICE_PROPERTY, 3, 3, false, true},
+	{ACTION_SERVICE_PROPERTY_CONTAINS, 3, 3, false, true},
+	{ACTION_JOURNAL_CONTAINS, 2, 2, false, true},
+	{ACTION_JOURNAL_MATCH, 2, 2, false, true},
+
 	{ACTION_HTTP_STATUS, 3, 4, false, true},
 	{ACTION_HTTP_HEADER, 4, 5, false, true},
 	{ACTION_HTTP_CONTAINS, 3, 4, false, true},
@@ -220,5 +345,14 @@ var Tokens = []TokenInfo{
 	{ACTION_PYTHON2_PACKAGE, 1, 1, false, false},
 	{ACTION_PYTHON3_PACKAGE, 1, 1, false, false},
 
+	{ACTION_MAX_RSS, 1, 2, false, true},
+	{ACTION_MAX_CPU_TIME, 1, 2, false, true},
+	{ACTION_MAX_IO_READ, 1, 2, false, true},
+	{ACTION_MAX_IO_WRITE, 1, 2, false, true},
+	{ACTION_MAX_DURATION, 1, 1, false, true},
+
+	{ACTION_PROC_SNAPSHOT, 1, 2, false, false},
+	{ACTION_PROC_GROWTH, 3, 4, false, true},
+
 	{ACTION_TEMPLATE, 2, 3, false, false},
 }
@@ -8,6 +8,9 @@ package render
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/essentialkaos/bibop/recipe"
 )
 
@@ -39,8 +42,72 @@ type Renderer interface {
 	// ActionDone prints info about successfully finished action
 	ActionDone(a *recipe.Action, isLast bool)
 
+	// HookStarted prints info about started setup, before-each or after-each hook
+	HookStarted(c *recipe.Command)
+
+	// HookDone prints info about successfully executed hook
+	HookDone(c *recipe.Command)
+
+	// HookFailed prints info about failed setup, before-each or after-each hook
+	HookFailed(c *recipe.Command, err error)
+
+	// Interrupted prints info about interrupted test
+	Interrupted(reason string)
+
+	// Coverage prints summary of coverage of Go binaries
+	Coverage(summary string)
+
 	// Result prints info about test results
 	Result(passes, fails, skips int)
 }
 
 // ////////////////////////////////////////////////////////////////////////////////// //
+
+// formatBenchmarkResult formats command benchmark results as plain text
+func formatBenchmarkResult(b *recipe.BenchmarkResult) string {
+	result := fmt.Sprintf(
+		"Benchmark: iterations=%d min=%gs mean=%gs p95=%gs",
+		b.Iterations, b.Min.Seconds(), b.Mean.Seconds(), b.P95.Seconds(),
+	)
+
+	if b.BaselineMean > 0 {
+		result += fmt.Sprintf(" baseline=%gs", b.BaselineMean.Seconds())
+	}
+
+	return result
+}
+
+// formatCrashSignal formats info about signal which crashed command process
+func formatCrashSignal(ci *recipe.CrashInfo) string {
+	if ci.CoreDumped {
+		return ci.Signal + " (core dumped)"
+	}
+
+	return ci.Signal
+}
+
+// formatFinding formats info about error found by memory checker
+func formatFinding(f *recipe.Finding) string {
+	if f.Location == "" {
+		return fmt.Sprintf("[%s] %s", f.Tool, f.Summary)
+	}
+
+	return fmt.Sprintf("[%s] %s at %s", f.Tool, f.Summary, f.Location)
+}
+
+// formatYAMLMessage formats error message as YAML value. Multiline messages are
+// formatted as literal block with given indent.
+func formatYAMLMessage(err error, indent string) string {
+	msg := err.Error()
+
+	if !strings.Contains(msg, "\n") {
+		return "'" + msg + "'"
+	}
+
+	return "|\n" + indent + strings.ReplaceAll(msg, "\n", "\n"+indent)
+}
+
+// indentMessage adds indent to all lines of message except the first one
+func indentMessage(err error, indent string) string {
+	return strings.ReplaceAll(err.Error(), "\n", "\n"+indent)
+}
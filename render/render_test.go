@@ -0,0 +1,170 @@
+package render
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/essentialkaos/bibop/recipe"
+
+	. "github.com/essentialkaos/check"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+func Test(t *testing.T) { TestingT(t) }
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+type RenderSuite struct{}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+var _ = Suite(&RenderSuite{})
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+func (s *RenderSuite) TestJUnit(c *C) {
+	var buf bytes.Buffer
+
+	dir := c.MkDir()
+	renderRecipe(&JUnitRenderer{Version: "1.0.0", Writer: &buf}, dir)
+
+	hostname, _ := os.Hostname()
+
+	output := strings.ReplaceAll(buf.String(), dir, "/srv/test")
+	output = strings.ReplaceAll(output, `hostname="`+hostname+`"`, `hostname="localhost"`)
+	output = regexp.MustCompile(` time="[0-9.]+"`).ReplaceAllString(output, ` time="0.000"`)
+	output = regexp.MustCompile(` timestamp="[0-9T:-]+"`).ReplaceAllString(output, ` timestamp="2025-01-01T00:00:00"`)
+
+	golden, err := os.ReadFile("../testdata/render/junit.xml")
+
+	c.Assert(err, IsNil)
+	c.Assert(output, Equals, string(golden))
+}
+
+func (s *RenderSuite) TestHTML(c *C) {
+	var buf bytes.Buffer
+
+	renderRecipe(&HTMLRenderer{Version: "1.0.0", Writer: &buf}, c.MkDir())
+
+	output := buf.String()
+
+	c.Assert(output, Not(Matches), "(?s).*<script>.*")
+	c.Assert(output, Not(Matches), "(?s).*<b>OK</b>.*")
+	c.Assert(output, Not(Matches), "(?s).*<html> &.*")
+
+	c.Assert(strings.Contains(output, "<pre>&lt;script&gt;alert(&#39;&amp;&#39;)&lt;/script&gt;\n"), Equals, true)
+	c.Assert(strings.Contains(output, "Check &lt;html&gt; &amp; &#34;output&#34;"), Equals, true)
+	c.Assert(strings.Contains(output, "expect &lt;b&gt;OK&lt;/b&gt;"), Equals, true)
+	c.Assert(strings.Contains(output, "Output doesn&#39;t contain &#34;&lt;b&gt;OK&lt;/b&gt;&#34;"), Equals, true)
+	c.Assert(strings.Contains(output, "failed: &lt;exit code 1&gt;"), Equals, true)
+	c.Assert(strings.Contains(output, "total: 81.5% &lt;of statements&gt;"), Equals, true)
+}
+
+func (s *RenderSuite) TestNDJSON(c *C) {
+	var buf bytes.Buffer
+
+	renderRecipe(&NDJSONRenderer{Writer: &buf}, c.MkDir())
+
+	var events []string
+	var coverage, result *event
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		e := &event{}
+
+		c.Assert(json.Unmarshal([]byte(line), e), IsNil, Commentf(line))
+		c.Assert(e.Timestamp.IsZero(), Equals, false)
+
+		switch e.Event {
+		case EVENT_COVERAGE:
+			coverage = e
+		case EVENT_RESULT:
+			result = e
+		}
+
+		events = append(events, e.Event)
+	}
+
+	c.Assert(events, DeepEquals, []string{
+		EVENT_START,
+		EVENT_HOOK_STARTED, EVENT_HOOK_DONE,
+		EVENT_COMMAND_STARTED, EVENT_ACTION_STARTED, EVENT_ACTION_DONE, EVENT_COMMAND_DONE,
+		EVENT_COMMAND_STARTED, EVENT_ACTION_STARTED, EVENT_ACTION_FAILED,
+		EVENT_COMMAND_SKIPPED,
+		EVENT_HOOK_STARTED, EVENT_HOOK_FAILED,
+		EVENT_COVERAGE,
+		EVENT_RESULT,
+	})
+
+	c.Assert(coverage.Coverage, Equals, "total: 81.5% <of statements>")
+	c.Assert(result.Result, NotNil)
+	c.Assert(result.Result.Passed, Equals, 1)
+	c.Assert(result.Result.Failed, Equals, 1)
+	c.Assert(result.Result.Skipped, Equals, 1)
+	c.Assert(result.Result.Coverage, Equals, "")
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// renderRecipe passes events of recipe execution to renderer
+func renderRecipe(rr Renderer, dir string) {
+	r := recipe.NewRecipe(dir + "/test.recipe")
+	r.Dir = dir
+
+	setup := recipe.NewCommand([]string{"mkdir data", "Create data directory"}, 3)
+	cmd1 := recipe.NewCommand([]string{"echo 'ABCD'", "Basic command"}, 6)
+	cmd2 := recipe.NewCommand([]string{"cat page.html", `Check <html> & "output"`}, 10)
+	cmd3 := recipe.NewCommand([]string{"cat page.txt"}, 14)
+	teardown := recipe.NewCommand([]string{"rm -rf data"}, 18)
+
+	r.AddCommand(setup, recipe.SETUP_TAG, false)
+	r.AddCommand(cmd1, "", false)
+	r.AddCommand(cmd2, "", false)
+	r.AddCommand(cmd3, "", true)
+	r.AddCommand(teardown, recipe.TEARDOWN_TAG, false)
+
+	exit := &recipe.Action{Name: "exit", Arguments: []string{"0"}, Line: 7}
+	expect := &recipe.Action{Name: "expect", Arguments: []string{"<b>OK</b>"}, Line: 11}
+	cmd1.AddAction(exit)
+	cmd2.AddAction(expect)
+
+	rr.Start(r)
+
+	setup.Started = time.Now()
+	rr.HookStarted(setup)
+	rr.HookDone(setup)
+
+	cmd1.Started = time.Now()
+	rr.CommandStarted(cmd1)
+	rr.ActionStarted(exit)
+	rr.ActionDone(exit, true)
+	rr.CommandDone(cmd1, false)
+
+	cmd2.Started = time.Now()
+	cmd2.Output = "<script>alert('&')</script>\n\x1b[1mDONE\x1b[0m"
+	rr.CommandStarted(cmd2)
+	rr.ActionStarted(expect)
+	rr.ActionFailed(expect, errors.New(`Output doesn't contain "<b>OK</b>"`))
+
+	rr.CommandSkipped(cmd3, false)
+
+	teardown.Started = time.Now()
+	rr.HookStarted(teardown)
+	rr.HookFailed(teardown, errors.New("Action \"exit\" (line 19) failed: <exit code 1>"))
+
+	rr.Coverage("total: 81.5% <of statements>")
+	rr.Result(1, 1, 1)
+}
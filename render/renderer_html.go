@@ -0,0 +1,553 @@
+package render
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/fmtutil"
+	"github.com/essentialkaos/ek/v13/strutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+const (
+	htmlStatusPassed  = "passed"
+	htmlStatusFailed  = "failed"
+	htmlStatusSkipped = "skipped"
+)
+
+// htmlStyles contains styles for HTML report
+const htmlStyles = `
+body { margin: 0; padding: 0 24px 24px; font: 14px/1.5 -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; color: #24292f; background: #f6f8fa; }
+h1 { margin: 0; padding: 20px 0 8px; font-size: 24px; }
+h2 { margin: 24px 0 8px; font-size: 18px; }
+code, pre { font: 12px/1.45 SFMono-Regular, Consolas, "Liberation Mono", Menlo, monospace; }
+pre { margin: 8px 0 0; padding: 8px 12px; overflow: auto; white-space: pre-wrap; word-break: break-all; background: #24292f; color: #f6f8fa; border-radius: 4px; }
+table { border-collapse: collapse; background: #fff; border: 1px solid #d0d7de; }
+th, td { padding: 4px 12px; text-align: left; vertical-align: top; border-bottom: 1px solid #eaeef2; }
+th { font-weight: 600; white-space: nowrap; }
+.summary span { display: inline-block; margin-right: 16px; font-weight: 600; }
+.interrupted { margin: 12px 0; padding: 8px 12px; background: #fff8c5; border: 1px solid #d4a72c; border-radius: 4px; }
+details { margin: 4px 0; background: #fff; border: 1px solid #d0d7de; border-left-width: 4px; border-radius: 4px; }
+details > summary { padding: 6px 12px; cursor: pointer; }
+details > div { padding: 0 12px 10px 32px; }
+details.passed { border-left-color: #2da44e; }
+details.failed { border-left-color: #cf222e; }
+details.skipped { border-left-color: #8c959f; }
+ul { margin: 4px 0; padding: 0; list-style: none; }
+li { padding: 2px 0; }
+.status { display: inline-block; width: 1.4em; font-weight: 700; }
+.passed > .status, .passed > summary > .status { color: #2da44e; }
+.failed > .status, .failed > summary > .status { color: #cf222e; }
+.skipped > .status, .skipped > summary > .status, .skipped code { color: #8c959f; }
+.meta, .time, .line, .tag { color: #57606a; font-size: 12px; }
+.tag { padding: 0 6px; border: 1px solid #d0d7de; border-radius: 10px; }
+.time, .line { margin-left: 8px; }
+.message { margin: 4px 0; color: #cf222e; white-space: pre-wrap; }
+.info { margin: 4px 0; color: #57606a; }
+footer { margin-top: 24px; color: #57606a; font-size: 12px; }
+`
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// HTMLRenderer is HTML renderer
+type HTMLRenderer struct {
+	Version string
+	Writer  io.Writer // Output writer (os.Stdout by default)
+
+	start           time.Time
+	recipe          *recipe.Recipe
+	commands        []*htmlCommand
+	hooks           []*htmlCommand
+	curCommand      *htmlCommand
+	interruptReason string
+	coverage        string
+}
+
+// htmlCommand contains info about command execution
+type htmlCommand struct {
+	source   *recipe.Command
+	status   string
+	message  string
+	duration time.Duration
+	actions  []*htmlAction
+}
+
+// htmlAction contains info about action execution
+type htmlAction struct {
+	status   string
+	message  string
+	duration time.Duration
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Start prints info about started test
+func (rr *HTMLRenderer) Start(r *recipe.Recipe) {
+	if rr.Writer == nil {
+		rr.Writer = os.Stdout
+	}
+
+	rr.start = time.Now()
+	rr.recipe = r
+}
+
+// CommandStarted prints info about started command
+func (rr *HTMLRenderer) CommandStarted(c *recipe.Command) {
+	rr.curCommand = &htmlCommand{source: c}
+	rr.commands = append(rr.commands, rr.curCommand)
+}
+
+// CommandSkipped prints info about skipped command
+func (rr *HTMLRenderer) CommandSkipped(c *recipe.Command, isLast bool) {
+	rr.curCommand = nil
+	rr.commands = append(rr.commands, &htmlCommand{source: c, status: htmlStatusSkipped})
+}
+
+// CommandFailed prints info about failed command
+func (rr *HTMLRenderer) CommandFailed(c *recipe.Command, err error) {
+	if rr.curCommand == nil || rr.curCommand.status != "" {
+		return
+	}
+
+	rr.curCommand.status = htmlStatusFailed
+	rr.curCommand.message = err.Error()
+	rr.curCommand.duration = time.Since(c.Started)
+}
+
+// CommandFailed prints info about executed command
+func (rr *HTMLRenderer) CommandDone(c *recipe.Command, isLast bool) {
+	if rr.curCommand == nil {
+		return
+	}
+
+	rr.curCommand.status = htmlStatusPassed
+	rr.curCommand.duration = time.Since(c.Started)
+}
+
+// ActionStarted prints info about action in progress
+func (rr *HTMLRenderer) ActionStarted(a *recipe.Action) {}
+
+// ActionFailed prints info about failed action
+func (rr *HTMLRenderer) ActionFailed(a *recipe.Action, err error) {
+	if rr.curCommand == nil {
+		return
+	}
+
+	rr.curCommand.actions = append(rr.curCommand.actions, &htmlAction{
+		status:   htmlStatusFailed,
+		message:  err.Error(),
+		duration: time.Since(a.Started),
+	})
+
+	rr.curCommand.status = htmlStatusFailed
+	rr.curCommand.duration = time.Since(a.Command.Started)
+}
+
+// ActionDone prints info about successfully finished action
+func (rr *HTMLRenderer) ActionDone(a *recipe.Action, isLast bool) {
+	if rr.curCommand == nil {
+		return
+	}
+
+	rr.curCommand.actions = append(rr.curCommand.actions, &htmlAction{
+		status:   htmlStatusPassed,
+		duration: time.Since(a.Started),
+	})
+}
+
+// HookStarted prints info about started hook
+func (rr *HTMLRenderer) HookStarted(c *recipe.Command) {}
+
+// HookDone prints info about successfully executed hook
+func (rr *HTMLRenderer) HookDone(c *recipe.Command) {}
+
+// HookFailed prints info about failed hook
+func (rr *HTMLRenderer) HookFailed(c *recipe.Command, err error) {
+	rr.hooks = append(rr.hooks, &htmlCommand{
+		source:   c,
+		status:   htmlStatusFailed,
+		message:  err.Error(),
+		duration: time.Since(c.Started),
+		actions:  rr.getHookActions(c, err),
+	})
+}
+
+// Interrupted prints info about interrupted test
+func (rr *HTMLRenderer) Interrupted(reason string) {
+	if rr.curCommand != nil && rr.curCommand.status == "" {
+		rr.curCommand.status = htmlStatusFailed
+		rr.curCommand.message = reason
+		rr.curCommand.duration = time.Since(rr.curCommand.source.Started)
+	}
+
+	rr.interruptReason = reason
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *HTMLRenderer) Coverage(summary string) {
+	rr.coverage = summary
+}
+
+// Result prints info about test results
+func (rr *HTMLRenderer) Result(passes, fails, skips int) {
+	var data strings.Builder
+
+	name := strutil.Exclude(filepath.Base(rr.recipe.File), ".recipe")
+
+	data.WriteString("<!DOCTYPE html>\n")
+	data.WriteString(fmt.Sprintf("<!-- bibop %s | recipe report -->\n", rr.Version))
+	data.WriteString("<html lang=\"en\">\n<head>\n")
+	data.WriteString("<meta charset=\"utf-8\">\n")
+	data.WriteString(fmt.Sprintf("<title>bibop report: %s</title>\n", html.EscapeString(name)))
+	data.WriteString("<style>" + htmlStyles + "</style>\n")
+	data.WriteString("</head>\n<body>\n")
+
+	data.WriteString(fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(name)))
+	data.WriteString("<div class=\"summary\">")
+	data.WriteString(fmt.Sprintf("<span class=\"passed\">Passed: %d</span>", passes))
+	data.WriteString(fmt.Sprintf("<span class=\"failed\">Failed: %d</span>", fails))
+	data.WriteString(fmt.Sprintf("<span class=\"skipped\">Skipped: %d</span>", skips))
+	data.WriteString(fmt.Sprintf("<span>Duration: %s</span>", formatHTMLDuration(time.Since(rr.start))))
+	data.WriteString("</div>\n")
+
+	if rr.interruptReason != "" {
+		data.WriteString(fmt.Sprintf(
+			"<div class=\"interrupted\">Recipe processing was interrupted: %s</div>\n",
+			html.EscapeString(rr.interruptReason),
+		))
+	}
+
+	rr.writeRecipeInfo(&data)
+
+	if rr.coverage != "" {
+		data.WriteString("<h2>Coverage</h2>\n")
+		data.WriteString(fmt.Sprintf("<pre>%s</pre>\n", html.EscapeString(rr.coverage)))
+	}
+
+	if len(rr.hooks) != 0 {
+		data.WriteString("<h2>Hook failures</h2>\n")
+
+		for _, h := range rr.hooks {
+			rr.writeCommand(&data, h)
+		}
+	}
+
+	data.WriteString("<h2>Commands</h2>\n")
+
+	for _, c := range rr.commands {
+		rr.writeCommand(&data, c)
+	}
+
+	data.WriteString(fmt.Sprintf(
+		"<footer>Generated by bibop %s at %s</footer>\n",
+		html.EscapeString(rr.Version), rr.start.Format("2006-01-02 15:04:05 MST"),
+	))
+	data.WriteString("</body>\n</html>")
+
+	fmt.Fprintln(rr.Writer, data.String())
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// writeRecipeInfo writes recipe metadata
+func (rr *HTMLRenderer) writeRecipeInfo(data *strings.Builder) {
+	r := rr.recipe
+
+	recipeFile, _ := filepath.Abs(r.File)
+	workingDir, _ := filepath.Abs(r.Dir)
+
+	data.WriteString("<h2>Recipe</h2>\n<table>\n")
+
+	rr.writeInfoRow(data, "Recipe file", recipeFile)
+	rr.writeInfoRow(data, "Working dir", workingDir)
+	rr.writeInfoRow(data, "Started", rr.start.Format("2006-01-02 15:04:05 MST"))
+	rr.writeInfoRow(data, "Unsafe actions", formatHTMLFlag(r.UnsafeActions))
+	rr.writeInfoRow(data, "Require root", formatHTMLFlag(r.RequireRoot))
+	rr.writeInfoRow(data, "Fast finish", formatHTMLFlag(r.FastFinish))
+	rr.writeInfoRow(data, "Lock workdir", formatHTMLFlag(r.LockWorkdir))
+	rr.writeInfoRow(data, "Unbuffered IO", formatHTMLFlag(r.Unbuffer))
+	rr.writeInfoRow(data, "HTTPS skip verify", formatHTMLFlag(r.HTTPSSkipVerify))
+	rr.writeInfoRow(data, "Auto restore", formatHTMLFlag(r.AutoRestore))
+
+	if r.Delay > 0 {
+		rr.writeInfoRow(data, "Delay", fmt.Sprintf("%g s", r.Delay))
+	}
+
+	if r.Deadline > 0 {
+		rr.writeInfoRow(data, "Deadline", fmt.Sprintf("%g s", r.Deadline))
+	}
+
+	if len(r.Packages) != 0 {
+		rr.writeInfoRow(data, "Packages", strings.Join(r.Packages, ", "))
+	}
+
+	data.WriteString("</table>\n")
+
+	variables := r.GetVariables()
+
+	if len(variables) == 0 {
+		return
+	}
+
+	data.WriteString("<h2>Variables</h2>\n<table>\n")
+
+	for _, v := range variables {
+		rr.writeInfoRow(data, v, r.GetVariable(v, true))
+	}
+
+	data.WriteString("</table>\n")
+}
+
+// writeInfoRow writes table row with name and value
+func (rr *HTMLRenderer) writeInfoRow(data *strings.Builder, name, value string) {
+	data.WriteString(fmt.Sprintf(
+		"<tr><th>%s</th><td>%s</td></tr>\n",
+		html.EscapeString(name), html.EscapeString(value),
+	))
+}
+
+// writeCommand writes info about command with all actions
+func (rr *HTMLRenderer) writeCommand(data *strings.Builder, hc *htmlCommand) {
+	c := hc.source
+	status := hc.status
+
+	// Command can be still running if recipe processing was interrupted
+	if status == "" {
+		status = htmlStatusFailed
+	}
+
+	if status == htmlStatusFailed {
+		data.WriteString(fmt.Sprintf("<details class=\"%s\" open>\n<summary>", status))
+	} else {
+		data.WriteString(fmt.Sprintf("<details class=\"%s\">\n<summary>", status))
+	}
+
+	data.WriteString(fmt.Sprintf("<span class=\"status\">%s</span>", formatHTMLStatus(status)))
+
+	if c.Description != "" {
+		data.WriteString(html.EscapeString(c.Description) + " ")
+	}
+
+	if c.IsHollow() {
+		data.WriteString("<code>—</code>")
+	} else {
+		data.WriteString("<code>" + html.EscapeString(c.GetCmdline()) + "</code>")
+	}
+
+	if c.User != "" {
+		data.WriteString(" <span class=\"tag\">user: " + html.EscapeString(c.User) + "</span>")
+	}
+
+	if c.Tag != "" {
+		data.WriteString(" <span class=\"tag\">" + html.EscapeString(c.Tag) + "</span>")
+	}
+
+	if status != htmlStatusSkipped {
+		data.WriteString(fmt.Sprintf("<span class=\"time\">%s</span>", formatHTMLDuration(hc.duration)))
+	}
+
+	data.WriteString(fmt.Sprintf("<span class=\"line\">line %d</span>", c.Line))
+	data.WriteString("</summary>\n<div>\n")
+
+	if len(c.Env) != 0 {
+		data.WriteString(fmt.Sprintf(
+			"<div class=\"info\">Environment: <code>%s</code></div>\n",
+			html.EscapeString(strings.Join(c.Env, " ")),
+		))
+	}
+
+	rr.writeActions(data, hc)
+
+	if hc.message != "" && !rr.hasFailedAction(hc) {
+		data.WriteString(fmt.Sprintf("<div class=\"message\">%s</div>\n", html.EscapeString(hc.message)))
+	}
+
+	rr.writeCommandInfo(data, c)
+
+	if status == htmlStatusFailed && c.Output != "" {
+		data.WriteString("<div class=\"info\">The last lines from command output:</div>\n")
+		data.WriteString("<pre>" + html.EscapeString(c.Output) + "</pre>\n")
+	}
+
+	data.WriteString("</div>\n</details>\n")
+}
+
+// writeActions writes info about command actions
+func (rr *HTMLRenderer) writeActions(data *strings.Builder, hc *htmlCommand) {
+	if len(hc.source.Actions) == 0 {
+		return
+	}
+
+	data.WriteString("<ul>\n")
+
+	for index, a := range hc.source.Actions {
+		ha := &htmlAction{status: htmlStatusSkipped}
+
+		if index < len(hc.actions) {
+			ha = hc.actions[index]
+		}
+
+		data.WriteString(fmt.Sprintf(
+			"<li class=\"%s\"><span class=\"status\">%s</span><code>%s</code>",
+			ha.status, formatHTMLStatus(ha.status), html.EscapeString(rr.formatAction(a)),
+		))
+
+		if ha.status != htmlStatusSkipped {
+			data.WriteString(fmt.Sprintf("<span class=\"time\">%s</span>", formatHTMLDuration(ha.duration)))
+		}
+
+		data.WriteString(fmt.Sprintf("<span class=\"line\">line %d</span>", a.Line))
+
+		if ha.message != "" {
+			data.WriteString(fmt.Sprintf("<div class=\"message\">%s</div>", html.EscapeString(ha.message)))
+		}
+
+		data.WriteString("</li>\n")
+	}
+
+	data.WriteString("</ul>\n")
+}
+
+// writeCommandInfo writes info about benchmark, resources usage, crash and
+// memory checkers findings
+func (rr *HTMLRenderer) writeCommandInfo(data *strings.Builder, c *recipe.Command) {
+	if c.Benchmark != nil {
+		data.WriteString(fmt.Sprintf(
+			"<div class=\"info\">%s</div>\n",
+			html.EscapeString(formatBenchmarkResult(c.Benchmark)),
+		))
+	}
+
+	if c.Usage != nil {
+		data.WriteString(fmt.Sprintf(
+			"<div class=\"info\">Max RSS: %s | User time: %s | System time: %s</div>\n",
+			fmtutil.PrettySize(c.Usage.MaxRSS),
+			formatHTMLDuration(c.Usage.UserTime),
+			formatHTMLDuration(c.Usage.SystemTime),
+		))
+	}
+
+	if c.Crash != nil {
+		data.WriteString(fmt.Sprintf(
+			"<div class=\"message\">Process crashed with signal %s</div>\n",
+			html.EscapeString(formatCrashSignal(c.Crash)),
+		))
+	}
+
+	for _, f := range c.Findings {
+		data.WriteString(fmt.Sprintf(
+			"<div class=\"message\">%s</div>\n<pre>%s</pre>\n",
+			html.EscapeString(formatFinding(f)), html.EscapeString(f.Report),
+		))
+	}
+}
+
+// getHookActions returns info about executed actions of failed hook. Hook
+// actions don't emit events, so executed actions are detected by start time
+// and the last executed action is the failed one.
+func (rr *HTMLRenderer) getHookActions(c *recipe.Command, err error) []*htmlAction {
+	var result []*htmlAction
+
+	for index, a := range c.Actions {
+		if a.Started.Before(c.Started) {
+			break
+		}
+
+		ha := &htmlAction{status: htmlStatusPassed}
+
+		if index+1 < len(c.Actions) && !c.Actions[index+1].Started.Before(c.Started) {
+			ha.duration = c.Actions[index+1].Started.Sub(a.Started)
+		} else {
+			ha.status, ha.duration = htmlStatusFailed, time.Since(a.Started)
+			ha.message = err.Error()
+		}
+
+		result = append(result, ha)
+	}
+
+	return result
+}
+
+// hasFailedAction returns true if command has failed action
+func (rr *HTMLRenderer) hasFailedAction(hc *htmlCommand) bool {
+	for _, a := range hc.actions {
+		if a.status == htmlStatusFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatAction formats action name with arguments
+func (rr *HTMLRenderer) formatAction(a *recipe.Action) string {
+	result := a.Name
+
+	if a.Negative {
+		result = "!" + result
+	}
+
+	for index := range a.Arguments {
+		arg, _ := a.GetS(index)
+
+		if strings.Contains(arg, " ") {
+			result += " \"" + arg + "\""
+		} else {
+			result += " " + arg
+		}
+	}
+
+	return result
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// formatHTMLStatus returns symbol for given status
+func formatHTMLStatus(status string) string {
+	switch status {
+	case htmlStatusPassed:
+		return "✔"
+	case htmlStatusFailed:
+		return "✖"
+	}
+
+	return "–"
+}
+
+// formatHTMLFlag formats option flag
+func formatHTMLFlag(flag bool) string {
+	if flag {
+		return "Yes"
+	}
+
+	return "No"
+}
+
+// formatHTMLDuration formats duration
+func formatHTMLDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%g s", fmtutil.Float(d.Seconds()))
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%g ms", fmtutil.Float(float64(d)/float64(time.Millisecond)))
+	case d >= time.Microsecond:
+		return fmt.Sprintf("%g μs", fmtutil.Float(float64(d)/float64(time.Microsecond)))
+	}
+
+	return fmt.Sprintf("%d ns", d.Nanoseconds())
+}
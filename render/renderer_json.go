@@ -10,6 +10,8 @@ package render
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -20,17 +22,22 @@ import (
 
 // JSONRenderer is JSON renderer
 type JSONRenderer struct {
-	start      time.Time
-	report     *report
-	curCommand *command
+	Writer io.Writer // Output writer (os.Stdout by default)
+
+	start           time.Time
+	report          *report
+	curCommand      *command
+	interruptReason string
+	coverage        string
 }
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 type report struct {
-	RecipeInfo *recipeInfo `json:"recipe"`
-	Commands   []*command  `json:"commands"`
-	Results    *results    `json:"result"`
+	RecipeInfo   *recipeInfo `json:"recipe"`
+	Commands     []*command  `json:"commands"`
+	HookFailures []*hook     `json:"hook_failures,omitempty"`
+	Results      *results    `json:"result"`
 }
 
 type recipeInfo struct {
@@ -44,14 +51,57 @@ type recipeInfo struct {
 }
 
 type command struct {
-	Actions      []*action `json:"actions,omitempty"`
-	User         string    `json:"user,omitempty"`
-	Tag          string    `json:"tag,omitempty"`
-	Cmdline      string    `json:"cmdline"`
-	Description  string    `json:"description"`
-	Env          []string  `json:"env,omitempty"`
-	ErrorMessage string    `json:"error_message,omitempty"`
-	IsFailed     bool      `json:"is_failed"`
+	Actions      []*action  `json:"actions,omitempty"`
+	User         string     `json:"user,omitempty"`
+	Tag          string     `json:"tag,omitempty"`
+	Cmdline      string     `json:"cmdline"`
+	Description  string     `json:"description"`
+	Env          []string   `json:"env,omitempty"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	IsFailed     bool       `json:"is_failed"`
+	Usage        *usage     `json:"usage,omitempty"`
+	Benchmark    *benchmark `json:"benchmark,omitempty"`
+	Crash        *crash     `json:"crash,omitempty"`
+	Findings     []*finding `json:"findings,omitempty"`
+
+	source *recipe.Command
+}
+
+type crash struct {
+	Signal     string `json:"signal"`
+	CoreDumped bool   `json:"core_dumped"`
+	CoreFile   string `json:"core_file,omitempty"`
+}
+
+type finding struct {
+	Tool     string `json:"tool"`
+	Summary  string `json:"summary"`
+	Location string `json:"location,omitempty"`
+	Report   string `json:"report"`
+}
+
+type benchmark struct {
+	Iterations   int     `json:"iterations"`
+	Min          float64 `json:"min"`
+	Mean         float64 `json:"mean"`
+	P95          float64 `json:"p95"`
+	BaselineMean float64 `json:"baseline_mean,omitempty"`
+}
+
+type usage struct {
+	MaxRSS     uint64  `json:"max_rss"`
+	UserTime   float64 `json:"user_time"`
+	SystemTime float64 `json:"system_time"`
+	IORead     uint64  `json:"io_read"`
+	IOWrite    uint64  `json:"io_write"`
+}
+
+type hook struct {
+	Type         string `json:"type"`
+	Cmdline      string `json:"cmdline"`
+	Description  string `json:"description"`
+	ErrorMessage string `json:"error_message"`
+	Line         uint16 `json:"line"`
 }
 
 type action struct {
@@ -62,16 +112,23 @@ type action struct {
 }
 
 type results struct {
-	Passed   int     `json:"passed"`
-	Failed   int     `json:"failed"`
-	Skipped  int     `json:"skipped"`
-	Duration float64 `json:"duration"`
+	Passed          int     `json:"passed"`
+	Failed          int     `json:"failed"`
+	Skipped         int     `json:"skipped"`
+	Duration        float64 `json:"duration"`
+	Interrupted     bool    `json:"interrupted"`
+	InterruptReason string  `json:"interrupt_reason,omitempty"`
+	Coverage        string  `json:"coverage,omitempty"`
 }
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 // Start prints info about started test
 func (rr *JSONRenderer) Start(r *recipe.Recipe) {
+	if rr.Writer == nil {
+		rr.Writer = os.Stdout
+	}
+
 	rr.start = time.Now()
 
 	rr.report = &report{}
@@ -89,6 +146,7 @@ func (rr *JSONRenderer) Start(r *recipe.Recipe) {
 
 // CommandStarted prints info about started command
 func (rr *JSONRenderer) CommandStarted(c *recipe.Command) {
+	rr.flushCommand()
 	rr.curCommand = rr.convertCommand(c)
 }
 
@@ -100,12 +158,12 @@ func (rr *JSONRenderer) CommandFailed(c *recipe.Command, err error) {
 	rr.curCommand.IsFailed = true
 	rr.curCommand.ErrorMessage = err.Error()
 
-	rr.report.Commands = append(rr.report.Commands, rr.curCommand)
+	rr.appendCommand()
 }
 
 // CommandFailed prints info about executed command
 func (rr *JSONRenderer) CommandDone(c *recipe.Command, isLast bool) {
-	rr.report.Commands = append(rr.report.Commands, rr.curCommand)
+	rr.appendCommand()
 }
 
 // ActionStarted prints info about action in progress
@@ -113,7 +171,7 @@ func (rr *JSONRenderer) ActionStarted(a *recipe.Action) {}
 
 // ActionFailed prints info about failed action
 func (rr *JSONRenderer) ActionFailed(a *recipe.Action, err error) {
-	action := rr.convertAction(a)
+	action := convertAction(a)
 
 	action.IsFailed = true
 	action.ErrorMessage = err.Error()
@@ -123,19 +181,94 @@ func (rr *JSONRenderer) ActionFailed(a *recipe.Action, err error) {
 
 // ActionDone prints info about successfully finished action
 func (rr *JSONRenderer) ActionDone(a *recipe.Action, isLast bool) {
-	rr.curCommand.Actions = append(rr.curCommand.Actions, rr.convertAction(a))
+	rr.curCommand.Actions = append(rr.curCommand.Actions, convertAction(a))
+}
+
+// HookStarted prints info about started hook
+func (rr *JSONRenderer) HookStarted(c *recipe.Command) {}
+
+// HookDone prints info about successfully executed hook
+func (rr *JSONRenderer) HookDone(c *recipe.Command) {}
+
+// HookFailed prints info about failed hook
+func (rr *JSONRenderer) HookFailed(c *recipe.Command, err error) {
+	rr.report.HookFailures = append(rr.report.HookFailures, &hook{
+		Type:         c.Tag,
+		Cmdline:      c.GetCmdline(),
+		Description:  c.Description,
+		ErrorMessage: err.Error(),
+		Line:         c.Line,
+	})
+}
+
+// Interrupted prints info about interrupted test
+func (rr *JSONRenderer) Interrupted(reason string) {
+	// Command was interrupted during action execution
+	if rr.curCommand != nil && !rr.isActionFailed(rr.curCommand) {
+		rr.curCommand.IsFailed = true
+		rr.curCommand.ErrorMessage = reason
+	}
+
+	rr.interruptReason = reason
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *JSONRenderer) Coverage(summary string) {
+	rr.coverage = summary
 }
 
 // Result prints info about test results
 func (rr *JSONRenderer) Result(passes, fails, skips int) {
-	rr.report.Results = &results{passes, fails, skips, time.Since(rr.start).Seconds()}
+	rr.flushCommand()
+
+	rr.report.Results = &results{
+		Passed:          passes,
+		Failed:          fails,
+		Skipped:         skips,
+		Duration:        time.Since(rr.start).Seconds(),
+		Interrupted:     rr.interruptReason != "",
+		InterruptReason: rr.interruptReason,
+		Coverage:        rr.coverage,
+	}
+
 	data, _ := json.MarshalIndent(rr.report, "", "  ")
-	fmt.Println(string(data))
+	fmt.Fprintln(rr.Writer, string(data))
 }
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
-// convertAction converts command to inner format
+// flushCommand adds info about current command with failed action to report
+func (rr *JSONRenderer) flushCommand() {
+	if rr.curCommand == nil {
+		return
+	}
+
+	rr.curCommand.IsFailed = true
+	rr.appendCommand()
+}
+
+// appendCommand adds info about current command to report
+func (rr *JSONRenderer) appendCommand() {
+	rr.curCommand.Usage = convertUsage(rr.curCommand.source.Usage)
+	rr.curCommand.Benchmark = convertBenchmark(rr.curCommand.source.Benchmark)
+	rr.curCommand.Crash = convertCrash(rr.curCommand.source.Crash)
+	rr.curCommand.Findings = convertFindings(rr.curCommand.source.Findings)
+	rr.report.Commands = append(rr.report.Commands, rr.curCommand)
+	rr.curCommand = nil
+}
+
+// isActionFailed returns true if command has failed action
+func (rr *JSONRenderer) isActionFailed(c *command) bool {
+	for _, a := range c.Actions {
+		if a.IsFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// convertCommand converts command to inner format
 func (rr *JSONRenderer) convertCommand(c *recipe.Command) *command {
 	return &command{
 		User:        c.User,
@@ -143,11 +276,73 @@ func (rr *JSONRenderer) convertCommand(c *recipe.Command) *command {
 		Cmdline:     c.GetCmdline(),
 		Description: c.Description,
 		Env:         c.Env,
+		source:      c,
+	}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// convertBenchmark converts benchmark results to inner format
+func convertBenchmark(b *recipe.BenchmarkResult) *benchmark {
+	if b == nil {
+		return nil
+	}
+
+	return &benchmark{
+		Iterations:   b.Iterations,
+		Min:          b.Min.Seconds(),
+		Mean:         b.Mean.Seconds(),
+		P95:          b.P95.Seconds(),
+		BaselineMean: b.BaselineMean.Seconds(),
+	}
+}
+
+// convertCrash converts info about crash to inner format
+func convertCrash(c *recipe.CrashInfo) *crash {
+	if c == nil {
+		return nil
+	}
+
+	return &crash{
+		Signal:     c.Signal,
+		CoreDumped: c.CoreDumped,
+		CoreFile:   c.CoreFile,
+	}
+}
+
+// convertFindings converts errors found by memory checkers to inner format
+func convertFindings(findings []*recipe.Finding) []*finding {
+	var result []*finding
+
+	for _, f := range findings {
+		result = append(result, &finding{
+			Tool:     f.Tool,
+			Summary:  f.Summary,
+			Location: f.Location,
+			Report:   f.Report,
+		})
+	}
+
+	return result
+}
+
+// convertUsage converts resource usage info to inner format
+func convertUsage(u *recipe.ResourceUsage) *usage {
+	if u == nil {
+		return nil
+	}
+
+	return &usage{
+		MaxRSS:     u.MaxRSS,
+		UserTime:   u.UserTime.Seconds(),
+		SystemTime: u.SystemTime.Seconds(),
+		IORead:     u.IORead,
+		IOWrite:    u.IOWrite,
 	}
 }
 
 // convertAction converts action to inner format
-func (rr *JSONRenderer) convertAction(a *recipe.Action) *action {
+func convertAction(a *recipe.Action) *action {
 	action := &action{}
 
 	if a.Negative {
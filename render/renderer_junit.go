@@ -0,0 +1,373 @@
+package render
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/essentialkaos/ek/v13/strutil"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// JUnitRenderer is JUnit XML renderer
+type JUnitRenderer struct {
+	Version string
+	Writer  io.Writer // Output writer (os.Stdout by default)
+
+	start time.Time
+	suite *junitSuite
+
+	curCommand  *recipe.Command
+	curFinished time.Time
+	curFailure  *junitFailure
+
+	lastFailed      *recipe.Command
+	hookFailure     string
+	interruptReason string
+	coverage        string
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+type junitSuites struct {
+	XMLName  xml.Name      `xml:"testsuites"`
+	Name     string        `xml:"name,attr"`
+	Tests    int           `xml:"tests,attr"`
+	Failures int           `xml:"failures,attr"`
+	Errors   int           `xml:"errors,attr"`
+	Skipped  int           `xml:"skipped,attr"`
+	Time     string        `xml:"time,attr"`
+	Suites   []*junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr"`
+	Timestamp  string           `xml:"timestamp,attr"`
+	Hostname   string           `xml:"hostname,attr"`
+	Properties []*junitProperty `xml:"properties>property"`
+	Cases      []*junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      uint16        `xml:"line,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *junitSkipped `xml:"skipped"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+
+	isError bool
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Start prints info about started test
+func (rr *JUnitRenderer) Start(r *recipe.Recipe) {
+	if rr.Writer == nil {
+		rr.Writer = os.Stdout
+	}
+
+	rr.start = time.Now()
+	rr.suite = &junitSuite{
+		Name:      strutil.Exclude(filepath.Base(r.File), ".recipe"),
+		Timestamp: rr.start.Format("2006-01-02T15:04:05"),
+	}
+
+	recipeFile, _ := filepath.Abs(r.File)
+	workingDir, _ := filepath.Abs(r.Dir)
+
+	rr.addProperty("bibop-version", rr.Version)
+	rr.addProperty("recipe-file", recipeFile)
+	rr.addProperty("working-dir", workingDir)
+	rr.addProperty("unsafe-actions", fmt.Sprint(r.UnsafeActions))
+	rr.addProperty("require-root", fmt.Sprint(r.RequireRoot))
+	rr.addProperty("fast-finish", fmt.Sprint(r.FastFinish))
+	rr.addProperty("lock-workdir", fmt.Sprint(r.LockWorkdir))
+	rr.addProperty("unbuffer", fmt.Sprint(r.Unbuffer))
+}
+
+// CommandStarted prints info about started command
+func (rr *JUnitRenderer) CommandStarted(c *recipe.Command) {
+	rr.closeCase()
+
+	rr.curCommand = c
+
+	// Failed before-each hook affects only commands from one group
+	if rr.hookFailure == recipe.BEFORE_EACH_TAG {
+		rr.hookFailure = ""
+	}
+}
+
+// CommandSkipped prints info about skipped command
+func (rr *JUnitRenderer) CommandSkipped(c *recipe.Command, isLast bool) {
+	rr.closeCase()
+
+	rr.suite.Skipped++
+
+	tc := rr.addCase(c, rr.getCommandName(c), 0)
+	tc.Skipped = &junitSkipped{Message: rr.getSkipReason(c)}
+}
+
+// CommandFailed prints info about failed command
+func (rr *JUnitRenderer) CommandFailed(c *recipe.Command, err error) {
+	rr.setFailure(c, false, "command", err.Error(), "")
+}
+
+// CommandFailed prints info about executed command
+func (rr *JUnitRenderer) CommandDone(c *recipe.Command, isLast bool) {
+	rr.curFinished = time.Now()
+	rr.closeCase()
+}
+
+// ActionStarted prints info about action in progress
+func (rr *JUnitRenderer) ActionStarted(a *recipe.Action) {}
+
+// ActionFailed prints info about failed action
+func (rr *JUnitRenderer) ActionFailed(a *recipe.Action, err error) {
+	details := fmt.Sprintf(
+		"Action: %s\nLine: %d\n\n%s",
+		rr.formatAction(a), a.Line, err.Error(),
+	)
+
+	rr.setFailure(a.Command, false, a.Name, err.Error(), details)
+}
+
+// ActionDone prints info about successfully finished action
+func (rr *JUnitRenderer) ActionDone(a *recipe.Action, isLast bool) {}
+
+// HookStarted prints info about started hook
+func (rr *JUnitRenderer) HookStarted(c *recipe.Command) {}
+
+// HookDone prints info about successfully executed hook
+func (rr *JUnitRenderer) HookDone(c *recipe.Command) {}
+
+// HookFailed prints info about failed hook
+func (rr *JUnitRenderer) HookFailed(c *recipe.Command, err error) {
+	rr.closeCase()
+
+	rr.suite.Errors++
+
+	tc := rr.addCase(c, c.Tag+" hook: "+rr.getCommandName(c), time.Since(c.Started))
+	tc.Error = &junitFailure{Message: err.Error(), Type: "hook", Details: err.Error()}
+
+	if c.Tag == recipe.SETUP_TAG || c.Tag == recipe.BEFORE_EACH_TAG {
+		rr.hookFailure = c.Tag
+	}
+}
+
+// Interrupted prints info about interrupted test
+func (rr *JUnitRenderer) Interrupted(reason string) {
+	if rr.curCommand != nil && rr.curFailure == nil {
+		rr.setFailure(rr.curCommand, true, "interrupted", reason, "")
+	}
+
+	rr.closeCase()
+
+	rr.interruptReason = reason
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *JUnitRenderer) Coverage(summary string) {
+	rr.coverage = summary
+}
+
+// Result prints info about test results
+func (rr *JUnitRenderer) Result(passes, fails, skips int) {
+	rr.closeCase()
+
+	if rr.interruptReason != "" {
+		rr.addProperty("interrupted", rr.interruptReason)
+	}
+
+	if rr.coverage != "" {
+		rr.addProperty("coverage", rr.coverage)
+	}
+
+	rr.suite.Hostname, _ = os.Hostname()
+	rr.suite.Tests = len(rr.suite.Cases)
+	rr.suite.Time = fmt.Sprintf("%.3f", time.Since(rr.start).Seconds())
+
+	data, _ := xml.MarshalIndent(&junitSuites{
+		Name:     "bibop",
+		Tests:    rr.suite.Tests,
+		Failures: rr.suite.Failures,
+		Errors:   rr.suite.Errors,
+		Skipped:  rr.suite.Skipped,
+		Time:     rr.suite.Time,
+		Suites:   []*junitSuite{rr.suite},
+	}, "", "  ")
+
+	fmt.Fprintln(rr.Writer, "<?xml version=\"1.0\" encoding=\"UTF-8\" ?>")
+	fmt.Fprintf(rr.Writer, "<!-- bibop %s | recipe report -->\n", rr.Version)
+	fmt.Fprintln(rr.Writer, string(data))
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// setFailure saves info about failure of current command
+func (rr *JUnitRenderer) setFailure(c *recipe.Command, isError bool, typ, message, details string) {
+	if rr.curCommand != c || rr.curFailure != nil {
+		return
+	}
+
+	if details == "" {
+		details = message
+	}
+
+	if c.Crash != nil {
+		details += "\n\nProcess crashed with signal " + formatCrashSignal(c.Crash)
+	}
+
+	for index, f := range c.Findings {
+		if index == 0 {
+			details += "\n"
+		}
+
+		details += "\n" + formatFinding(f)
+	}
+
+	if isError {
+		rr.suite.Errors++
+	} else {
+		rr.suite.Failures++
+	}
+
+	rr.lastFailed = c
+	rr.curFinished = time.Now()
+	rr.curFailure = &junitFailure{
+		Message: message,
+		Type:    typ,
+		Details: details,
+		isError: isError,
+	}
+}
+
+// addCase adds new test case to suite
+func (rr *JUnitRenderer) addCase(c *recipe.Command, name string, duration time.Duration) *junitCase {
+	tc := &junitCase{
+		Name:      name,
+		Classname: rr.suite.Name,
+		File:      c.Recipe.File,
+		Line:      c.Line,
+		Time:      fmt.Sprintf("%.3f", duration.Seconds()),
+	}
+
+	rr.suite.Cases = append(rr.suite.Cases, tc)
+
+	return tc
+}
+
+// closeCase adds info about current command
+func (rr *JUnitRenderer) closeCase() {
+	if rr.curCommand == nil {
+		return
+	}
+
+	c := rr.curCommand
+
+	if rr.curFinished.IsZero() {
+		rr.curFinished = time.Now()
+	}
+
+	tc := rr.addCase(c, rr.getCommandName(c), rr.curFinished.Sub(c.Started))
+
+	if rr.curFailure != nil {
+		if rr.curFailure.isError {
+			tc.Error = rr.curFailure
+		} else {
+			tc.Failure = rr.curFailure
+		}
+
+		tc.SystemOut = c.Output
+	}
+
+	rr.curCommand, rr.curFailure, rr.curFinished = nil, nil, time.Time{}
+}
+
+// addProperty adds test suite property
+func (rr *JUnitRenderer) addProperty(name, value string) {
+	rr.suite.Properties = append(rr.suite.Properties, &junitProperty{name, value})
+}
+
+// getSkipReason returns message with the reason why command was skipped
+func (rr *JUnitRenderer) getSkipReason(c *recipe.Command) string {
+	switch {
+	case rr.interruptReason != "":
+		return "Skipped due to interruption: " + rr.interruptReason
+	case rr.hookFailure != "":
+		return fmt.Sprintf("Skipped due to failure of %s hook", rr.hookFailure)
+	case rr.lastFailed != nil && rr.lastFailed.GroupID == c.GroupID:
+		return fmt.Sprintf("Skipped due to failure of command at line %d", rr.lastFailed.Line)
+	case c.Tag != "":
+		return fmt.Sprintf("Skipped because tag %q is not selected", c.Tag)
+	case rr.lastFailed != nil && c.Recipe.FastFinish:
+		return "Skipped due to failure of previous command (fast-finish mode)"
+	}
+
+	return "Skipped"
+}
+
+// getCommandName returns name of test case for command
+func (rr *JUnitRenderer) getCommandName(c *recipe.Command) string {
+	if c.Description != "" {
+		return c.Description
+	}
+
+	if c.IsHollow() {
+		return fmt.Sprintf("Command at line %d", c.Line)
+	}
+
+	return c.GetCmdline()
+}
+
+// formatAction formats action name with arguments
+func (rr *JUnitRenderer) formatAction(a *recipe.Action) string {
+	name := a.Name
+
+	if a.Negative {
+		name = "!" + name
+	}
+
+	for index := range a.Arguments {
+		arg, _ := a.GetS(index)
+		name += " " + arg
+	}
+
+	return name
+}
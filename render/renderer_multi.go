@@ -0,0 +1,100 @@
+package render
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// MultiRenderer is renderer which passes all events to several renderers
+type MultiRenderer struct {
+	Renderers []Renderer
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Start prints info about started test
+func (rr *MultiRenderer) Start(r *recipe.Recipe) {
+	rr.each(func(rn Renderer) { rn.Start(r) })
+}
+
+// CommandStarted prints info about started command
+func (rr *MultiRenderer) CommandStarted(c *recipe.Command) {
+	rr.each(func(r Renderer) { r.CommandStarted(c) })
+}
+
+// CommandSkipped prints info about skipped command
+func (rr *MultiRenderer) CommandSkipped(c *recipe.Command, isLast bool) {
+	rr.each(func(r Renderer) { r.CommandSkipped(c, isLast) })
+}
+
+// CommandFailed prints info about failed command
+func (rr *MultiRenderer) CommandFailed(c *recipe.Command, err error) {
+	rr.each(func(r Renderer) { r.CommandFailed(c, err) })
+}
+
+// CommandFailed prints info about executed command
+func (rr *MultiRenderer) CommandDone(c *recipe.Command, isLast bool) {
+	rr.each(func(r Renderer) { r.CommandDone(c, isLast) })
+}
+
+// ActionStarted prints info about action in progress
+func (rr *MultiRenderer) ActionStarted(a *recipe.Action) {
+	rr.each(func(r Renderer) { r.ActionStarted(a) })
+}
+
+// ActionFailed prints info about failed action
+func (rr *MultiRenderer) ActionFailed(a *recipe.Action, err error) {
+	rr.each(func(r Renderer) { r.ActionFailed(a, err) })
+}
+
+// ActionDone prints info about successfully finished action
+func (rr *MultiRenderer) ActionDone(a *recipe.Action, isLast bool) {
+	rr.each(func(r Renderer) { r.ActionDone(a, isLast) })
+}
+
+// HookStarted prints info about started hook
+func (rr *MultiRenderer) HookStarted(c *recipe.Command) {
+	rr.each(func(r Renderer) { r.HookStarted(c) })
+}
+
+// HookDone prints info about successfully executed hook
+func (rr *MultiRenderer) HookDone(c *recipe.Command) {
+	rr.each(func(r Renderer) { r.HookDone(c) })
+}
+
+// HookFailed prints info about failed hook
+func (rr *MultiRenderer) HookFailed(c *recipe.Command, err error) {
+	rr.each(func(r Renderer) { r.HookFailed(c, err) })
+}
+
+// Interrupted prints info about interrupted test
+func (rr *MultiRenderer) Interrupted(reason string) {
+	rr.each(func(r Renderer) { r.Interrupted(reason) })
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *MultiRenderer) Coverage(summary string) {
+	rr.each(func(r Renderer) { r.Coverage(summary) })
+}
+
+// Result prints info about test results
+func (rr *MultiRenderer) Result(passes, fails, skips int) {
+	rr.each(func(r Renderer) { r.Result(passes, fails, skips) })
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// each calls given function for every renderer
+func (rr *MultiRenderer) each(fn func(r Renderer)) {
+	for _, r := range rr.Renderers {
+		fn(r)
+	}
+}
@@ -0,0 +1,285 @@
+package render
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+//                                                                                    //
+//                         Copyright (c) 2025 ESSENTIAL KAOS                          //
+//      Apache License, Version 2.0 <https://www.apache.org/licenses/LICENSE-2.0>     //
+//                                                                                    //
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/essentialkaos/bibop/recipe"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Event types
+const (
+	EVENT_START           = "start"
+	EVENT_COMMAND_STARTED = "command-started"
+	EVENT_COMMAND_SKIPPED = "command-skipped"
+	EVENT_COMMAND_FAILED  = "command-failed"
+	EVENT_COMMAND_DONE    = "command-done"
+	EVENT_ACTION_STARTED  = "action-started"
+	EVENT_ACTION_FAILED   = "action-failed"
+	EVENT_ACTION_DONE     = "action-done"
+	EVENT_HOOK_STARTED    = "hook-started"
+	EVENT_HOOK_DONE       = "hook-done"
+	EVENT_HOOK_FAILED     = "hook-failed"
+	EVENT_INTERRUPTED     = "interrupted"
+	EVENT_COVERAGE        = "coverage"
+	EVENT_RESULT          = "result"
+)
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// NDJSONRenderer is newline delimited JSON renderer which prints event for every
+// renderer callback as soon as it happens
+type NDJSONRenderer struct {
+	Writer io.Writer // Output writer (os.Stdout by default)
+
+	start           time.Time
+	interruptReason string
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+type event struct {
+	Event     string        `json:"event"`
+	Timestamp time.Time     `json:"timestamp"`
+	Recipe    *recipeInfo   `json:"recipe,omitempty"`
+	Command   *eventCommand `json:"command,omitempty"`
+	Action    *eventAction  `json:"action,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Duration  float64       `json:"duration,omitempty"`
+	Coverage  string        `json:"coverage,omitempty"`
+	Result    *results      `json:"result,omitempty"`
+}
+
+type eventCommand struct {
+	Line        uint16     `json:"line"`
+	Tag         string     `json:"tag,omitempty"`
+	User        string     `json:"user,omitempty"`
+	Cmdline     string     `json:"cmdline"`
+	Description string     `json:"description"`
+	Usage       *usage     `json:"usage,omitempty"`
+	Benchmark   *benchmark `json:"benchmark,omitempty"`
+	Crash       *crash     `json:"crash,omitempty"`
+	Findings    []*finding `json:"findings,omitempty"`
+	Output      string     `json:"output,omitempty"`
+}
+
+type eventAction struct {
+	Line        uint16   `json:"line"`
+	CommandLine uint16   `json:"command_line"`
+	Name        string   `json:"name"`
+	Arguments   []string `json:"arguments"`
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// Start prints info about started test
+func (rr *NDJSONRenderer) Start(r *recipe.Recipe) {
+	if rr.Writer == nil {
+		rr.Writer = os.Stdout
+	}
+
+	rr.start = time.Now()
+
+	info := &recipeInfo{
+		UnsafeActions: r.UnsafeActions,
+		RequireRoot:   r.RequireRoot,
+		FastFinish:    r.FastFinish,
+		LockWorkdir:   r.LockWorkdir,
+		Unbuffer:      r.Unbuffer,
+	}
+
+	info.RecipeFile, _ = filepath.Abs(r.File)
+	info.WorkingDir, _ = filepath.Abs(r.Dir)
+
+	rr.printEvent(&event{Event: EVENT_START, Timestamp: rr.start, Recipe: info})
+}
+
+// CommandStarted prints info about started command
+func (rr *NDJSONRenderer) CommandStarted(c *recipe.Command) {
+	rr.printEvent(&event{
+		Event:   EVENT_COMMAND_STARTED,
+		Command: rr.convertCommand(c, false),
+	})
+}
+
+// CommandSkipped prints info about skipped command
+func (rr *NDJSONRenderer) CommandSkipped(c *recipe.Command, isLast bool) {
+	rr.printEvent(&event{
+		Event:   EVENT_COMMAND_SKIPPED,
+		Command: rr.convertCommand(c, false),
+	})
+}
+
+// CommandFailed prints info about failed command
+func (rr *NDJSONRenderer) CommandFailed(c *recipe.Command, err error) {
+	rr.printEvent(&event{
+		Event:    EVENT_COMMAND_FAILED,
+		Command:  rr.convertCommand(c, true),
+		Error:    err.Error(),
+		Duration: rr.getDuration(c.Started),
+	})
+}
+
+// CommandFailed prints info about executed command
+func (rr *NDJSONRenderer) CommandDone(c *recipe.Command, isLast bool) {
+	rr.printEvent(&event{
+		Event:    EVENT_COMMAND_DONE,
+		Command:  rr.convertCommand(c, true),
+		Duration: rr.getDuration(c.Started),
+	})
+}
+
+// ActionStarted prints info about action in progress
+func (rr *NDJSONRenderer) ActionStarted(a *recipe.Action) {
+	rr.printEvent(&event{
+		Event:  EVENT_ACTION_STARTED,
+		Action: rr.convertAction(a),
+	})
+}
+
+// ActionFailed prints info about failed action
+func (rr *NDJSONRenderer) ActionFailed(a *recipe.Action, err error) {
+	rr.printEvent(&event{
+		Event:    EVENT_ACTION_FAILED,
+		Action:   rr.convertAction(a),
+		Command:  rr.convertCommand(a.Command, true),
+		Error:    err.Error(),
+		Duration: rr.getDuration(a.Started),
+	})
+}
+
+// ActionDone prints info about successfully finished action
+func (rr *NDJSONRenderer) ActionDone(a *recipe.Action, isLast bool) {
+	rr.printEvent(&event{
+		Event:    EVENT_ACTION_DONE,
+		Action:   rr.convertAction(a),
+		Duration: rr.getDuration(a.Started),
+	})
+}
+
+// HookStarted prints info about started hook
+func (rr *NDJSONRenderer) HookStarted(c *recipe.Command) {
+	rr.printEvent(&event{
+		Event:   EVENT_HOOK_STARTED,
+		Command: rr.convertCommand(c, false),
+	})
+}
+
+// HookDone prints info about successfully executed hook
+func (rr *NDJSONRenderer) HookDone(c *recipe.Command) {
+	rr.printEvent(&event{
+		Event:    EVENT_HOOK_DONE,
+		Command:  rr.convertCommand(c, false),
+		Duration: rr.getDuration(c.Started),
+	})
+}
+
+// HookFailed prints info about failed hook
+func (rr *NDJSONRenderer) HookFailed(c *recipe.Command, err error) {
+	rr.printEvent(&event{
+		Event:    EVENT_HOOK_FAILED,
+		Command:  rr.convertCommand(c, true),
+		Error:    err.Error(),
+		Duration: rr.getDuration(c.Started),
+	})
+}
+
+// Interrupted prints info about interrupted test
+func (rr *NDJSONRenderer) Interrupted(reason string) {
+	rr.interruptReason = reason
+	rr.printEvent(&event{Event: EVENT_INTERRUPTED, Error: reason})
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *NDJSONRenderer) Coverage(summary string) {
+	rr.printEvent(&event{Event: EVENT_COVERAGE, Coverage: summary})
+}
+
+// Result prints info about test results
+func (rr *NDJSONRenderer) Result(passes, fails, skips int) {
+	rr.printEvent(&event{
+		Event: EVENT_RESULT,
+		Result: &results{
+			Passed:          passes,
+			Failed:          fails,
+			Skipped:         skips,
+			Duration:        time.Since(rr.start).Seconds(),
+			Interrupted:     rr.interruptReason != "",
+			InterruptReason: rr.interruptReason,
+		},
+	})
+}
+
+// ////////////////////////////////////////////////////////////////////////////////// //
+
+// printEvent prints event as a single line of JSON
+func (rr *NDJSONRenderer) printEvent(e *event) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(e)
+
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(rr.Writer, string(data))
+}
+
+// getDuration returns duration in seconds since given moment
+func (rr *NDJSONRenderer) getDuration(start time.Time) float64 {
+	if start.IsZero() {
+		return 0
+	}
+
+	return time.Since(start).Seconds()
+}
+
+// convertCommand converts command to event format
+func (rr *NDJSONRenderer) convertCommand(c *recipe.Command, withResults bool) *eventCommand {
+	result := &eventCommand{
+		Line:        c.Line,
+		Tag:         c.Tag,
+		User:        c.User,
+		Cmdline:     c.GetCmdline(),
+		Description: c.Description,
+	}
+
+	if !withResults {
+		return result
+	}
+
+	result.Usage = convertUsage(c.Usage)
+	result.Benchmark = convertBenchmark(c.Benchmark)
+	result.Crash = convertCrash(c.Crash)
+	result.Findings = convertFindings(c.Findings)
+	result.Output = c.Output
+
+	return result
+}
+
+// convertAction converts action to event format
+func (rr *NDJSONRenderer) convertAction(a *recipe.Action) *eventAction {
+	action := convertAction(a)
+
+	return &eventAction{
+		Line:        a.Line,
+		CommandLine: a.Command.Line,
+		Name:        action.Name,
+		Arguments:   action.Arguments,
+	}
+}
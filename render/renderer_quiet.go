@@ -42,5 +42,20 @@ func (rr *QuietRenderer) ActionFailed(a *recipe.Action, err error) {}
 // ActionDone prints info about successfully finished action
 func (rr *QuietRenderer) ActionDone(a *recipe.Action, isLast bool) {}
 
+// HookStarted prints info about started hook
+func (rr *QuietRenderer) HookStarted(c *recipe.Command) {}
+
+// HookDone prints info about successfully executed hook
+func (rr *QuietRenderer) HookDone(c *recipe.Command) {}
+
+// HookFailed prints info about failed hook
+func (rr *QuietRenderer) HookFailed(c *recipe.Command, err error) {}
+
+// Interrupted prints info about interrupted test
+func (rr *QuietRenderer) Interrupted(reason string) {}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *QuietRenderer) Coverage(summary string) {}
+
 // Result prints info about test results
 func (rr *QuietRenderer) Result(passes, fails, skips int) {}
@@ -9,6 +9,8 @@ package render
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -20,6 +22,7 @@ import (
 // TAP13Renderer is Test Anything Protocol v13 compatible renderer
 type TAP13Renderer struct {
 	Version string
+	Writer  io.Writer // Output writer (os.Stdout by default)
 
 	index int
 }
@@ -28,38 +31,42 @@ type TAP13Renderer struct {
 
 // Start prints info about started test
 func (rr *TAP13Renderer) Start(r *recipe.Recipe) {
-	fmt.Println("TAP version 13")
-	fmt.Printf("1..%d\n", rr.getTestCount(r))
+	if rr.Writer == nil {
+		rr.Writer = os.Stdout
+	}
+
+	fmt.Fprintln(rr.Writer, "TAP version 13")
+	fmt.Fprintf(rr.Writer, "1..%d\n", rr.getTestCount(r))
 
 	recipeFile, _ := filepath.Abs(r.File)
 	workingDir, _ := filepath.Abs(r.Dir)
 
-	fmt.Println("#")
-	fmt.Printf("# RECIPE INFO | bibop %s\n", rr.Version)
-	fmt.Printf("# Recipe file: %s\n", recipeFile)
-	fmt.Printf("# Working dir: %s\n", workingDir)
-	fmt.Printf("# Unsafe actions: %t\n", r.UnsafeActions)
-	fmt.Printf("# Require root: %t\n", r.RequireRoot)
-	fmt.Printf("# Fast finish: %t\n", r.FastFinish)
-	fmt.Printf("# Lock workdir: %t\n", r.LockWorkdir)
-	fmt.Printf("# Unbuffered IO: %t\n", r.Unbuffer)
+	fmt.Fprintln(rr.Writer, "#")
+	fmt.Fprintf(rr.Writer, "# RECIPE INFO | bibop %s\n", rr.Version)
+	fmt.Fprintf(rr.Writer, "# Recipe file: %s\n", recipeFile)
+	fmt.Fprintf(rr.Writer, "# Working dir: %s\n", workingDir)
+	fmt.Fprintf(rr.Writer, "# Unsafe actions: %t\n", r.UnsafeActions)
+	fmt.Fprintf(rr.Writer, "# Require root: %t\n", r.RequireRoot)
+	fmt.Fprintf(rr.Writer, "# Fast finish: %t\n", r.FastFinish)
+	fmt.Fprintf(rr.Writer, "# Lock workdir: %t\n", r.LockWorkdir)
+	fmt.Fprintf(rr.Writer, "# Unbuffered IO: %t\n", r.Unbuffer)
 
 	rr.index = 1
 }
 
 // CommandStarted prints info about started command
 func (rr *TAP13Renderer) CommandStarted(c *recipe.Command) {
-	fmt.Println("#")
-	fmt.Println("# " + rr.getCommandInfo(c))
+	fmt.Fprintln(rr.Writer, "#")
+	fmt.Fprintln(rr.Writer, "# "+rr.getCommandInfo(c))
 }
 
 // CommandSkipped prints info about skipped command
 func (rr *TAP13Renderer) CommandSkipped(c *recipe.Command, isLast bool) {
-	fmt.Println("#")
-	fmt.Println("# " + rr.getCommandInfo(c))
+	fmt.Fprintln(rr.Writer, "#")
+	fmt.Fprintln(rr.Writer, "# "+rr.getCommandInfo(c))
 
 	for _, a := range c.Actions {
-		fmt.Printf(
+		fmt.Fprintf(rr.Writer,
 			"ok %d - %s %s # SKIP\n",
 			rr.index,
 			rr.formatActionName(a),
@@ -72,32 +79,48 @@ func (rr *TAP13Renderer) CommandSkipped(c *recipe.Command, isLast bool) {
 
 // CommandFailed prints info about failed command
 func (rr *TAP13Renderer) CommandFailed(c *recipe.Command, err error) {
-	fmt.Printf("Bail out! %v\n", err)
+	fmt.Fprintf(rr.Writer, "Bail out! %v\n", err)
 }
 
 // CommandFailed prints info about executed command
-func (rr *TAP13Renderer) CommandDone(c *recipe.Command, isLast bool) {}
+func (rr *TAP13Renderer) CommandDone(c *recipe.Command, isLast bool) {
+	if c.Benchmark != nil {
+		fmt.Fprintln(rr.Writer, "# "+formatBenchmarkResult(c.Benchmark))
+	}
+}
 
 // ActionInProgress prints info about action in progress
 func (rr *TAP13Renderer) ActionStarted(a *recipe.Action) {}
 
 // ActionFailed prints info about failed action
 func (rr *TAP13Renderer) ActionFailed(a *recipe.Action, err error) {
-	fmt.Printf(
+	fmt.Fprintf(rr.Writer,
 		"not ok %d - %s %s\n",
 		rr.index,
 		rr.formatActionName(a),
 		rr.formatActionArgs(a),
 	)
-	fmt.Print("  ---\n")
-	fmt.Printf("  message: '%v'\n", err)
+	fmt.Fprint(rr.Writer, "  ---\n")
+	fmt.Fprintf(rr.Writer, "  message: %s\n", formatYAMLMessage(err, "    "))
+
+	if a.Command.Crash != nil {
+		fmt.Fprintf(rr.Writer, "  crash: '%s'\n", formatCrashSignal(a.Command.Crash))
+	}
+
+	if len(a.Command.Findings) != 0 {
+		fmt.Fprint(rr.Writer, "  findings:\n")
+
+		for _, f := range a.Command.Findings {
+			fmt.Fprintf(rr.Writer, "    - '%s'\n", strings.ReplaceAll(formatFinding(f), "'", "''"))
+		}
+	}
 
 	rr.index++
 }
 
 // ActionDone prints info about successfully finished action
 func (rr *TAP13Renderer) ActionDone(a *recipe.Action, isLast bool) {
-	fmt.Printf(
+	fmt.Fprintf(rr.Writer,
 		"ok %d - %s %s\n",
 		rr.index,
 		rr.formatActionName(a),
@@ -107,11 +130,39 @@ func (rr *TAP13Renderer) ActionDone(a *recipe.Action, isLast bool) {
 	rr.index++
 }
 
+// HookStarted prints info about started hook
+func (rr *TAP13Renderer) HookStarted(c *recipe.Command) {}
+
+// HookDone prints info about successfully executed hook
+func (rr *TAP13Renderer) HookDone(c *recipe.Command) {}
+
+// HookFailed prints info about failed hook
+func (rr *TAP13Renderer) HookFailed(c *recipe.Command, err error) {
+	fmt.Fprintln(rr.Writer, "#")
+	fmt.Fprintf(rr.Writer, "# Hook failed: %s\n", rr.getCommandInfo(c))
+	fmt.Fprintf(rr.Writer, "#   %v\n", err)
+}
+
+// Interrupted prints info about interrupted test
+func (rr *TAP13Renderer) Interrupted(reason string) {
+	fmt.Fprintf(rr.Writer, "Bail out! %s\n", reason)
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *TAP13Renderer) Coverage(summary string) {
+	fmt.Fprintln(rr.Writer, "#")
+	fmt.Fprintln(rr.Writer, "# Coverage:")
+
+	for _, line := range strings.Split(summary, "\n") {
+		fmt.Fprintf(rr.Writer, "#   %s\n", strings.TrimSpace(line))
+	}
+}
+
 // Result prints info about test results
 func (rr *TAP13Renderer) Result(passes, fails, skips int) {
-	fmt.Println("#")
-	fmt.Println("#")
-	fmt.Printf(
+	fmt.Fprintln(rr.Writer, "#")
+	fmt.Fprintln(rr.Writer, "#")
+	fmt.Fprintf(rr.Writer,
 		"# Passed: %d | Failed: %d | Skipped: %d\n\n",
 		passes, fails, skips,
 	)
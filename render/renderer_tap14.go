@@ -9,6 +9,8 @@ package render
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -20,6 +22,7 @@ import (
 // TAP14Renderer is Test Anything Protocol v14 compatible renderer
 type TAP14Renderer struct {
 	Version string
+	Writer  io.Writer // Output writer (os.Stdout by default)
 
 	commandFailed bool
 }
@@ -28,49 +31,57 @@ type TAP14Renderer struct {
 
 // Start prints info about started test
 func (rr *TAP14Renderer) Start(r *recipe.Recipe) {
-	fmt.Println("TAP version 14")
-	fmt.Printf("1..%d\n", len(r.Commands))
+	if rr.Writer == nil {
+		rr.Writer = os.Stdout
+	}
+
+	fmt.Fprintln(rr.Writer, "TAP version 14")
+	fmt.Fprintf(rr.Writer, "1..%d\n", len(r.Commands))
 
 	recipeFile, _ := filepath.Abs(r.File)
 	workingDir, _ := filepath.Abs(r.Dir)
 
-	fmt.Println("")
-	fmt.Printf("# RECIPE INFO | bibop %s\n", rr.Version)
-	fmt.Printf("# Recipe file: %s\n", recipeFile)
-	fmt.Printf("# Working dir: %s\n", workingDir)
-	fmt.Printf("# Unsafe actions: %t\n", r.UnsafeActions)
-	fmt.Printf("# Require root: %t\n", r.RequireRoot)
-	fmt.Printf("# Fast finish: %t\n", r.FastFinish)
-	fmt.Printf("# Lock workdir: %t\n", r.LockWorkdir)
-	fmt.Printf("# Unbuffered IO: %t\n", r.Unbuffer)
+	fmt.Fprintln(rr.Writer, "")
+	fmt.Fprintf(rr.Writer, "# RECIPE INFO | bibop %s\n", rr.Version)
+	fmt.Fprintf(rr.Writer, "# Recipe file: %s\n", recipeFile)
+	fmt.Fprintf(rr.Writer, "# Working dir: %s\n", workingDir)
+	fmt.Fprintf(rr.Writer, "# Unsafe actions: %t\n", r.UnsafeActions)
+	fmt.Fprintf(rr.Writer, "# Require root: %t\n", r.RequireRoot)
+	fmt.Fprintf(rr.Writer, "# Fast finish: %t\n", r.FastFinish)
+	fmt.Fprintf(rr.Writer, "# Lock workdir: %t\n", r.LockWorkdir)
+	fmt.Fprintf(rr.Writer, "# Unbuffered IO: %t\n", r.Unbuffer)
 }
 
 // CommandStarted prints info about started command
 func (rr *TAP14Renderer) CommandStarted(c *recipe.Command) {
-	fmt.Println("")
-	fmt.Printf("# Subtest: %s\n", rr.getCommandInfo(c))
-	fmt.Printf("    1..%d\n", len(c.Actions))
+	fmt.Fprintln(rr.Writer, "")
+	fmt.Fprintf(rr.Writer, "# Subtest: %s\n", rr.getCommandInfo(c))
+	fmt.Fprintf(rr.Writer, "    1..%d\n", len(c.Actions))
 
 	rr.commandFailed = false
 }
 
 // CommandSkipped prints info about skipped command
 func (rr *TAP14Renderer) CommandSkipped(c *recipe.Command, isLast bool) {
-	fmt.Println("")
-	fmt.Printf("ok %d - %s # SKIP\n", c.Index()+1, rr.getCommandInfo(c))
+	fmt.Fprintln(rr.Writer, "")
+	fmt.Fprintf(rr.Writer, "ok %d - %s # SKIP\n", c.Index()+1, rr.getCommandInfo(c))
 }
 
 // CommandFailed prints info about failed command
 func (rr *TAP14Renderer) CommandFailed(c *recipe.Command, err error) {
-	fmt.Printf("Bail out! %v\n", err)
+	fmt.Fprintf(rr.Writer, "Bail out! %v\n", err)
 }
 
 // CommandFailed prints info about executed command
 func (rr *TAP14Renderer) CommandDone(c *recipe.Command, isLast bool) {
+	if c.Benchmark != nil {
+		fmt.Fprintln(rr.Writer, "    # "+formatBenchmarkResult(c.Benchmark))
+	}
+
 	if rr.commandFailed {
-		fmt.Printf("not ok %d - %s\n", c.Index()+1, rr.getCommandInfo(c))
+		fmt.Fprintf(rr.Writer, "not ok %d - %s\n", c.Index()+1, rr.getCommandInfo(c))
 	} else {
-		fmt.Printf("ok %d - %s\n", c.Index()+1, rr.getCommandInfo(c))
+		fmt.Fprintf(rr.Writer, "ok %d - %s\n", c.Index()+1, rr.getCommandInfo(c))
 	}
 }
 
@@ -79,21 +90,33 @@ func (rr *TAP14Renderer) ActionStarted(a *recipe.Action) {}
 
 // ActionFailed prints info about failed action
 func (rr *TAP14Renderer) ActionFailed(a *recipe.Action, err error) {
-	fmt.Printf(
+	fmt.Fprintf(rr.Writer,
 		"    not ok %d - %s %s\n",
 		a.Index()+1,
 		rr.formatActionName(a),
 		rr.formatActionArgs(a),
 	)
-	fmt.Print("      ---\n")
-	fmt.Printf("      message: '%v'\n", err)
+	fmt.Fprint(rr.Writer, "      ---\n")
+	fmt.Fprintf(rr.Writer, "      message: %s\n", formatYAMLMessage(err, "        "))
+
+	if a.Command.Crash != nil {
+		fmt.Fprintf(rr.Writer, "      crash: '%s'\n", formatCrashSignal(a.Command.Crash))
+	}
+
+	if len(a.Command.Findings) != 0 {
+		fmt.Fprint(rr.Writer, "      findings:\n")
+
+		for _, f := range a.Command.Findings {
+			fmt.Fprintf(rr.Writer, "        - '%s'\n", strings.ReplaceAll(formatFinding(f), "'", "''"))
+		}
+	}
 
 	rr.commandFailed = true
 }
 
 // ActionDone prints info about successfully finished action
 func (rr *TAP14Renderer) ActionDone(a *recipe.Action, isLast bool) {
-	fmt.Printf(
+	fmt.Fprintf(rr.Writer,
 		"    ok %d - %s %s\n",
 		a.Index()+1,
 		rr.formatActionName(a),
@@ -101,10 +124,38 @@ func (rr *TAP14Renderer) ActionDone(a *recipe.Action, isLast bool) {
 	)
 }
 
+// HookStarted prints info about started hook
+func (rr *TAP14Renderer) HookStarted(c *recipe.Command) {}
+
+// HookDone prints info about successfully executed hook
+func (rr *TAP14Renderer) HookDone(c *recipe.Command) {}
+
+// HookFailed prints info about failed hook
+func (rr *TAP14Renderer) HookFailed(c *recipe.Command, err error) {
+	fmt.Fprintln(rr.Writer, "")
+	fmt.Fprintf(rr.Writer, "# Hook failed: %s\n", rr.getCommandInfo(c))
+	fmt.Fprintf(rr.Writer, "#   %v\n", err)
+}
+
+// Interrupted prints info about interrupted test
+func (rr *TAP14Renderer) Interrupted(reason string) {
+	fmt.Fprintf(rr.Writer, "Bail out! %s\n", reason)
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *TAP14Renderer) Coverage(summary string) {
+	fmt.Fprintln(rr.Writer, "#")
+	fmt.Fprintln(rr.Writer, "# Coverage:")
+
+	for _, line := range strings.Split(summary, "\n") {
+		fmt.Fprintf(rr.Writer, "#   %s\n", strings.TrimSpace(line))
+	}
+}
+
 // Result prints info about test results
 func (rr *TAP14Renderer) Result(passes, fails, skips int) {
-	fmt.Println("")
-	fmt.Printf(
+	fmt.Fprintln(rr.Writer, "")
+	fmt.Fprintf(rr.Writer,
 		"# Passed: %d | Failed: %d | Skipped: %d\n\n",
 		passes, fails, skips,
 	)
@@ -18,6 +18,7 @@ import (
 	"github.com/essentialkaos/ek/v13/fmtutil"
 	"github.com/essentialkaos/ek/v13/strutil"
 	"github.com/essentialkaos/ek/v13/terminal/tty"
+	"github.com/essentialkaos/ek/v13/timeutil"
 
 	"github.com/essentialkaos/bibop/recipe"
 )
@@ -38,6 +39,9 @@ type TerminalRenderer struct {
 	syncChan   chan uint8
 	isStarted  bool
 	isFinished bool
+	isAnimated bool
+	hookFails  int
+	coverage   string
 
 	PrintExecTime bool
 }
@@ -85,12 +89,16 @@ func (rr *TerminalRenderer) CommandSkipped(c *recipe.Command, isLast bool) {
 
 // CommandFailed prints info about failed command
 func (rr *TerminalRenderer) CommandFailed(c *recipe.Command, err error) {
+	rr.printBenchmarkResult(c)
+
 	fmtc.NewLine()
 	fmtc.Printfn("  {r}%v{!}", err)
 }
 
 // CommandFailed prints info about executed command
-func (rr *TerminalRenderer) CommandDone(c *recipe.Command, isLast bool) {}
+func (rr *TerminalRenderer) CommandDone(c *recipe.Command, isLast bool) {
+	rr.printBenchmarkResult(c)
+}
 
 // ActionInProgress prints info about action in progress
 func (rr *TerminalRenderer) ActionStarted(a *recipe.Action) {
@@ -105,12 +113,15 @@ func (rr *TerminalRenderer) ActionStarted(a *recipe.Action) {
 
 	// Wait until animation started
 	<-rr.syncChan
+
+	rr.isAnimated = true
 }
 
 // ActionFailed prints info about failed action
 func (rr *TerminalRenderer) ActionFailed(a *recipe.Action, err error) {
 	if !isCI {
 		rr.syncChan <- _ANIMATION_STOP
+		rr.isAnimated = false
 	}
 
 	var execTime string
@@ -128,13 +139,22 @@ func (rr *TerminalRenderer) ActionFailed(a *recipe.Action, err error) {
 		fmtc.NewLine()
 	}
 
-	fmtc.Printfn("     {r}%v{!}", err)
+	fmtc.Printfn("     {r}%s{!}", indentMessage(err, "     "))
+
+	if a.Command.Crash != nil {
+		fmtc.Printfn("     {r*}Process crashed with signal %s{!}", formatCrashSignal(a.Command.Crash))
+	}
+
+	for _, f := range a.Command.Findings {
+		fmtc.Printfn("     {r}• %s{!}", formatFinding(f))
+	}
 }
 
 // ActionDone prints info about successfully finished action
 func (rr *TerminalRenderer) ActionDone(a *recipe.Action, isLast bool) {
 	if !isCI {
 		rr.syncChan <- _ANIMATION_STOP
+		rr.isAnimated = false
 	}
 
 	var execTime string
@@ -160,6 +180,61 @@ func (rr *TerminalRenderer) ActionDone(a *recipe.Action, isLast bool) {
 	}
 }
 
+// HookStarted prints info about started hook
+func (rr *TerminalRenderer) HookStarted(c *recipe.Command) {
+	fmtc.NewLine()
+
+	if !isCI {
+		rr.renderTmpMessage("  {s-}●  Hook:{!} " + rr.formatCommandInfo(c))
+	}
+}
+
+// HookDone prints info about successfully executed hook
+func (rr *TerminalRenderer) HookDone(c *recipe.Command) {
+	rr.renderTmpMessage("  {g}✔  Hook:{!} " + rr.formatCommandInfo(c))
+
+	if !isCI {
+		fmtc.NewLine()
+	}
+}
+
+// HookFailed prints info about failed hook
+func (rr *TerminalRenderer) HookFailed(c *recipe.Command, err error) {
+	rr.renderTmpMessage("  {r}✖  Hook failed:{!} " + rr.formatCommandInfo(c))
+
+	if !isCI {
+		fmtc.NewLine()
+	}
+
+	fmtc.Printfn("     {r}%s{!}", indentMessage(err, "     "))
+
+	rr.hookFails++
+}
+
+// Interrupted prints info about interrupted test
+func (rr *TerminalRenderer) Interrupted(reason string) {
+	if rr.isAnimated {
+		rr.syncChan <- _ANIMATION_STOP
+		rr.isAnimated = false
+
+		rr.renderTmpMessage(
+			"  {s-}└─{!} {y}●  {!}"+rr.formatActionName(rr.curAction)+" {s}%s{!}",
+			rr.formatActionArgs(rr.curAction),
+		)
+
+		fmtc.NewLine()
+	}
+
+	fmtc.NewLine()
+	fmtc.Printfn("  {y}▲ %s{!}", reason)
+	fmtc.NewLine()
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *TerminalRenderer) Coverage(summary string) {
+	rr.coverage = summary
+}
+
 // Result prints info about test results
 func (rr *TerminalRenderer) Result(passes, fails, skips int) {
 	if rr.isFinished {
@@ -184,6 +259,19 @@ func (rr *TerminalRenderer) Result(passes, fails, skips int) {
 		fmtc.Printfn("  {*}Skipped:{!} {s}%d{!}", skips)
 	}
 
+	if rr.hookFails != 0 {
+		fmtc.Printfn("  {*}Failed hooks:{!} {r}%d{!}", rr.hookFails)
+	}
+
+	if rr.coverage != "" {
+		fmtc.NewLine()
+		fmtc.Println("  {*}Coverage:{!}")
+
+		for _, line := range strings.Split(rr.coverage, "\n") {
+			fmtc.Printfn("    {s}%s{!}", strings.TrimSpace(line))
+		}
+	}
+
 	d := rr.formatDuration(time.Since(rr.start), true)
 	d = strings.ReplaceAll(d, ".", "{s-}.") + "{!}"
 
@@ -225,6 +313,28 @@ func (rr *TerminalRenderer) printOptionFlag(name string, flag bool) {
 	}
 }
 
+// printBenchmarkResult prints command benchmark results
+func (rr *TerminalRenderer) printBenchmarkResult(c *recipe.Command) {
+	if c.Benchmark == nil {
+		return
+	}
+
+	b := c.Benchmark
+
+	fmtc.NewLine()
+	fmtc.Printf(
+		"  {s}Benchmark ({*}%d{!*} iterations):{!} min {*}%s{!} {s-}|{!} mean {*}%s{!} {s-}|{!} p95 {*}%s{!}",
+		b.Iterations, timeutil.PrettyDuration(b.Min),
+		timeutil.PrettyDuration(b.Mean), timeutil.PrettyDuration(b.P95),
+	)
+
+	if b.BaselineMean > 0 {
+		fmtc.Printf(" {s-}(baseline mean: %s){!}", timeutil.PrettyDuration(b.BaselineMean))
+	}
+
+	fmtc.NewLine()
+}
+
 // formatDuration formats duration
 func (rr *TerminalRenderer) formatDuration(d time.Duration, withMS bool) string {
 	var m, s, ms time.Duration
@@ -9,6 +9,8 @@ package render
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -23,15 +25,26 @@ import (
 // XMLRenderer is XML renderer
 type XMLRenderer struct {
 	Version string
-
-	start time.Time
-	data  strings.Builder
+	Writer  io.Writer // Output writer (os.Stdout by default)
+
+	start           time.Time
+	data            strings.Builder
+	hooksData       strings.Builder
+	interruptReason string
+	coverage        string
+	curCommand      *recipe.Command
+	isCommandOpen   bool
+	isActionOpen    bool
 }
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 // Start prints info about started test
 func (rr *XMLRenderer) Start(r *recipe.Recipe) {
+	if rr.Writer == nil {
+		rr.Writer = os.Stdout
+	}
+
 	rr.start = time.Now()
 
 	rr.data.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\" ?>\n")
@@ -55,6 +68,11 @@ func (rr *XMLRenderer) Start(r *recipe.Recipe) {
 
 // CommandStarted prints info about started command
 func (rr *XMLRenderer) CommandStarted(c *recipe.Command) {
+	rr.closeCommand("")
+
+	rr.isCommandOpen = true
+	rr.curCommand = c
+
 	rr.data.WriteString("    <command")
 
 	if c.User != "" {
@@ -92,20 +110,26 @@ func (rr *XMLRenderer) CommandSkipped(c *recipe.Command, isLast bool) {}
 
 // CommandFailed prints info about failed command
 func (rr *XMLRenderer) CommandFailed(c *recipe.Command, err error) {
-	rr.data.WriteString("      </actions>\n")
-	rr.data.WriteString(fmt.Sprintf("      <status failed=\"true\">%v</status>\n", err))
-	rr.data.WriteString("    </command>\n")
+	rr.closeCommand(err.Error())
 }
 
 // CommandFailed prints info about executed command
 func (rr *XMLRenderer) CommandDone(c *recipe.Command, isLast bool) {
 	rr.data.WriteString("      </actions>\n")
+	rr.writeUsage(c.Usage)
+	rr.writeBenchmark(c.Benchmark)
+	rr.writeCrash(c.Crash)
+	rr.writeFindings(c.Findings)
 	rr.data.WriteString("      <status failed=\"false\"></status>\n")
 	rr.data.WriteString("    </command>\n")
+
+	rr.isCommandOpen = false
 }
 
 // ActionStarted prints info about action in progress
 func (rr *XMLRenderer) ActionStarted(a *recipe.Action) {
+	rr.isActionOpen = true
+
 	rr.data.WriteString("        <action>\n")
 	rr.data.WriteString(fmt.Sprintf("          <name>%s</name>\n", rr.formatActionName(a)))
 	rr.data.WriteString("          <arguments>\n")
@@ -123,38 +147,171 @@ func (rr *XMLRenderer) ActionStarted(a *recipe.Action) {
 
 // ActionFailed prints info about failed action
 func (rr *XMLRenderer) ActionFailed(a *recipe.Action, err error) {
-	rr.data.WriteString(fmt.Sprintf("          <status failed=\"true\">%v</status>\n", err))
+	rr.data.WriteString(fmt.Sprintf("          <status failed=\"true\">%s</status>\n", rr.escapeData(err.Error())))
 	rr.data.WriteString("        </action>\n")
+
+	rr.isActionOpen = false
 }
 
 // ActionDone prints info about successfully finished action
 func (rr *XMLRenderer) ActionDone(a *recipe.Action, isLast bool) {
 	rr.data.WriteString("          <status failed=\"false\"></status>\n")
 	rr.data.WriteString("        </action>\n")
+
+	rr.isActionOpen = false
+}
+
+// HookStarted prints info about started hook
+func (rr *XMLRenderer) HookStarted(c *recipe.Command) {}
+
+// HookDone prints info about successfully executed hook
+func (rr *XMLRenderer) HookDone(c *recipe.Command) {}
+
+// HookFailed prints info about failed hook
+func (rr *XMLRenderer) HookFailed(c *recipe.Command, err error) {
+	rr.hooksData.WriteString(fmt.Sprintf("    <hook type=%q line=\"%d\">\n", c.Tag, c.Line))
+	rr.hooksData.WriteString(fmt.Sprintf("      <cmdline>%s</cmdline>\n", rr.escapeData(c.GetCmdline())))
+	rr.hooksData.WriteString(fmt.Sprintf("      <description>%s</description>\n", rr.escapeData(c.Description)))
+	rr.hooksData.WriteString(fmt.Sprintf("      <status failed=\"true\">%s</status>\n", rr.escapeData(err.Error())))
+	rr.hooksData.WriteString("    </hook>\n")
+}
+
+// Interrupted prints info about interrupted test
+func (rr *XMLRenderer) Interrupted(reason string) {
+	if rr.isActionOpen {
+		rr.data.WriteString(fmt.Sprintf("          <status failed=\"true\">%s</status>\n", rr.escapeData(reason)))
+		rr.data.WriteString("        </action>\n")
+
+		rr.isActionOpen = false
+	}
+
+	rr.closeCommand(reason)
+
+	rr.interruptReason = reason
+}
+
+// Coverage prints summary of coverage of Go binaries
+func (rr *XMLRenderer) Coverage(summary string) {
+	rr.coverage = summary
 }
 
 // Result prints info about test results
 func (rr *XMLRenderer) Result(passes, fails, skips int) {
+	rr.closeCommand("")
+
 	rr.data.WriteString("  </commands>\n")
+
+	if rr.hooksData.Len() != 0 {
+		rr.data.WriteString("  <hook-failures>\n")
+		rr.data.WriteString(rr.hooksData.String())
+		rr.data.WriteString("  </hook-failures>\n")
+	}
+
+	if rr.interruptReason != "" {
+		rr.data.WriteString(fmt.Sprintf(
+			"  <interrupted>%s</interrupted>\n",
+			rr.escapeData(rr.interruptReason),
+		))
+	}
+
+	if rr.coverage != "" {
+		rr.data.WriteString(fmt.Sprintf(
+			"  <coverage>%s</coverage>\n",
+			rr.escapeData(rr.coverage),
+		))
+	}
+
 	rr.data.WriteString(fmt.Sprintf(
-		"  <result passed=\"%d\" failed=\"%d\" skipped=\"%d\" duration=\"%g\" />\n",
-		passes, fails, skips, time.Since(rr.start).Seconds(),
+		"  <result passed=\"%d\" failed=\"%d\" skipped=\"%d\" duration=\"%g\" interrupted=\"%t\" />\n",
+		passes, fails, skips, time.Since(rr.start).Seconds(), rr.interruptReason != "",
 	))
 	rr.data.WriteString("</report>")
 
-	fmt.Println(rr.data.String())
+	fmt.Fprintln(rr.Writer, rr.data.String())
 }
 
 // ////////////////////////////////////////////////////////////////////////////////// //
 
 func (rr *XMLRenderer) escapeData(data string) string {
+	data = strings.ReplaceAll(data, "&", "&amp;")
 	data = strings.ReplaceAll(data, "<", "&lt;")
 	data = strings.ReplaceAll(data, ">", "&gt;")
-	data = strings.ReplaceAll(data, "&", "&amp;")
+	data = strings.ReplaceAll(data, "\"", "&quot;")
 
 	return data
 }
 
+// closeCommand closes command element with failed status if it is still open
+func (rr *XMLRenderer) closeCommand(message string) {
+	if !rr.isCommandOpen {
+		return
+	}
+
+	rr.data.WriteString("      </actions>\n")
+	rr.writeUsage(rr.curCommand.Usage)
+	rr.writeBenchmark(rr.curCommand.Benchmark)
+	rr.writeCrash(rr.curCommand.Crash)
+	rr.writeFindings(rr.curCommand.Findings)
+	rr.data.WriteString(fmt.Sprintf("      <status failed=\"true\">%s</status>\n", rr.escapeData(message)))
+	rr.data.WriteString("    </command>\n")
+
+	rr.isCommandOpen = false
+}
+
+// writeUsage writes info about resources used by command process
+func (rr *XMLRenderer) writeUsage(u *recipe.ResourceUsage) {
+	if u == nil {
+		return
+	}
+
+	rr.data.WriteString(fmt.Sprintf(
+		"      <usage max-rss=\"%d\" user-time=\"%g\" system-time=\"%g\" io-read=\"%d\" io-write=\"%d\" />\n",
+		u.MaxRSS, u.UserTime.Seconds(), u.SystemTime.Seconds(), u.IORead, u.IOWrite,
+	))
+}
+
+// writeBenchmark writes command benchmark results
+func (rr *XMLRenderer) writeBenchmark(b *recipe.BenchmarkResult) {
+	if b == nil {
+		return
+	}
+
+	rr.data.WriteString(fmt.Sprintf(
+		"      <benchmark iterations=\"%d\" min=\"%g\" mean=\"%g\" p95=\"%g\" baseline-mean=\"%g\" />\n",
+		b.Iterations, b.Min.Seconds(), b.Mean.Seconds(), b.P95.Seconds(), b.BaselineMean.Seconds(),
+	))
+}
+
+// writeCrash writes info about command process crash
+func (rr *XMLRenderer) writeCrash(c *recipe.CrashInfo) {
+	if c == nil {
+		return
+	}
+
+	rr.data.WriteString(fmt.Sprintf(
+		"      <crash signal=\"%s\" core-dumped=\"%t\" core-file=\"%s\" />\n",
+		c.Signal, c.CoreDumped, rr.escapeData(c.CoreFile),
+	))
+}
+
+// writeFindings writes errors found by memory checkers
+func (rr *XMLRenderer) writeFindings(findings []*recipe.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+
+	rr.data.WriteString("      <findings>\n")
+
+	for _, f := range findings {
+		rr.data.WriteString(fmt.Sprintf(
+			"        <finding tool=\"%s\" summary=\"%s\" location=\"%s\">%s</finding>\n",
+			f.Tool, rr.escapeData(f.Summary), rr.escapeData(f.Location), rr.escapeData(f.Report),
+		))
+	}
+
+	rr.data.WriteString("      </findings>\n")
+}
+
 // formatActionName format action name
 func (rr *XMLRenderer) formatActionName(a *recipe.Action) string {
 	if a.Negative {